@@ -46,6 +46,10 @@ func Run(logger log.Logger, streams cmd.IOStreams, args []string) error {
 		// only streams.Out should be written to (program output)
 		logger = log.NoopLogger{}
 		streams.ErrOut = ioutil.Discard
+	} else if checkOutputEvents(args) == "json" {
+		// NOTE: we handle the output events flag here as well so that the
+		// logger can be swapped before any commands are constructed
+		logger = cmd.NewJSONLogger(streams.ErrOut)
 	}
 	// actually run the command
 	c := kind.NewCommand(logger, streams)
@@ -77,6 +81,23 @@ func checkQuiet(args []string) bool {
 	return quiet
 }
 
+// checkOutputEvents returns the value of --output-events in args
+func checkOutputEvents(args []string) string {
+	flags := pflag.NewFlagSet("persistent-output-events", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	format := ""
+	flags.StringVar(
+		&format,
+		"output-events",
+		"",
+		"structured event output format",
+	)
+	// see checkQuiet
+	flags.Usage = func() {}
+	_ = flags.Parse(args)
+	return format
+}
+
 // logError logs the error and the root stacktrace if there is one
 func logError(logger log.Logger, err error) {
 	if cmd.ColorEnabled(logger) {
@@ -29,3 +29,8 @@ hack/release/build/cross.sh
 cd "${REPO_ROOT}/images/kindnetd"
 "${REPO_ROOT}/hack/go_container.sh" go build -v -o /out/kindnetd ./cmd/kindnetd
 "${REPO_ROOT}/hack/go_container.sh" go test -v ./...
+
+# build and test servicelb
+cd "${REPO_ROOT}/images/servicelb"
+"${REPO_ROOT}/hack/go_container.sh" go build -v -o /out/servicelb ./cmd/servicelb
+"${REPO_ROOT}/hack/go_container.sh" go test -v ./...
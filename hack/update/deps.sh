@@ -26,4 +26,5 @@ cd "${REPO_ROOT}"
 # tidy all modules
 hack/go_container.sh go mod tidy
 SOURCE_DIR="${REPO_ROOT}/hack/tools" hack/go_container.sh go mod tidy
-SOURCE_DIR="${REPO_ROOT}/cmd/kindnetd" hack/go_container.sh go mod tidy
\ No newline at end of file
+SOURCE_DIR="${REPO_ROOT}/cmd/kindnetd" hack/go_container.sh go mod tidy
+SOURCE_DIR="${REPO_ROOT}/images/servicelb" hack/go_container.sh go mod tidy
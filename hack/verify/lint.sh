@@ -40,3 +40,6 @@ GO111MODULE=on bin/golangci-lint --disable-all --enable="${ENABLE}" run ./pkg/..
 # ... and then for kindnetd, which is only on linux
 SOURCE_DIR="${REPO_ROOT}/images/kindnetd" GOOS="linux" "${REPO_ROOT}/hack/go_container.sh" \
   /out/golangci-lint-linux --disable-all --enable="${ENABLE}" run ./...
+# ... and servicelb, which is also only on linux
+SOURCE_DIR="${REPO_ROOT}/images/servicelb" GOOS="linux" "${REPO_ROOT}/hack/go_container.sh" \
+  /out/golangci-lint-linux --disable-all --enable="${ENABLE}" run ./...
@@ -17,14 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"text/template"
 
 	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
 	"k8s.io/utils/net"
 )
 
@@ -32,26 +37,115 @@ import (
 
 // CNIConfigInputs is supplied to the CNI config template
 type CNIConfigInputs struct {
-	PodCIDR      string
-	DefaultRoute string
+	// PodCIDRs has one CIDR per IP family the node supports
+	PodCIDRs []string
+	// DefaultRoutes has one default route per entry in PodCIDRs
+	DefaultRoutes []string
+	// MTU is the MTU of the node's interface, used for the pod interfaces
+	// 0 leaves the MTU to the ptp plugin's default
+	MTU int
+	// ExtraPlugins are additional plugin configs in JSON, chained after the
+	// default plugins
+	ExtraPlugins []string
 }
 
 // ComputeCNIConfigInputs computes the template inputs for CNIConfigWriter
-func ComputeCNIConfigInputs(node corev1.Node) CNIConfigInputs {
-	podCIDR := node.Spec.PodCIDR
-	defaultRoute := "0.0.0.0/0"
-	if net.IsIPv6CIDRString(podCIDR) {
-		defaultRoute = "::/0"
+// for node, which has the IP hostIP
+func ComputeCNIConfigInputs(node corev1.Node, hostIP string) (CNIConfigInputs, error) {
+	podCIDRs := nodePodCIDRs(node)
+	defaultRoutes := make([]string, 0, len(podCIDRs))
+	for _, podCIDR := range podCIDRs {
+		defaultRoute := "0.0.0.0/0"
+		if net.IsIPv6CIDRString(podCIDR) {
+			defaultRoute = "::/0"
+		}
+		defaultRoutes = append(defaultRoutes, defaultRoute)
+	}
+	mtu, err := interfaceMTU(hostIP)
+	if err != nil {
+		return CNIConfigInputs{}, errors.Wrap(err, "failed to detect MTU")
+	}
+	// a broken ConfigMap must not keep the node from getting a CNI config
+	extraPlugins, err := readExtraPlugins(cniExtraPluginsPath, cniBinDir)
+	if err != nil {
+		klog.Errorf("Ignoring extra CNI plugins, failed to read them from %s: %v", cniExtraPluginsPath, err)
+		extraPlugins = nil
 	}
 	return CNIConfigInputs{
-		PodCIDR:      podCIDR,
-		DefaultRoute: defaultRoute,
+		PodCIDRs:      podCIDRs,
+		DefaultRoutes: defaultRoutes,
+		MTU:           mtu,
+		ExtraPlugins:  extraPlugins,
+	}, nil
+}
+
+// interfaceMTU returns the MTU of the interface with the address ip
+func interfaceMTU(ip string) (int, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return 0, err
+	}
+	for _, link := range links {
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return 0, err
+		}
+		for _, addr := range addrs {
+			if addr.IP.String() == ip {
+				return link.Attrs().MTU, nil
+			}
+		}
+	}
+	return 0, errors.Errorf("no interface has the address %s", ip)
+}
+
+// readExtraPlugins reads a JSON list of CNI plugin configs from path,
+// returning each plugin config, if path exists
+// plugins that are not installed in binDir are skipped, as chaining them
+// would fail creating every pod
+func readExtraPlugins(path, binDir string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw := []json.RawMessage{}
+	if err := json.Unmarshal(contents, &raw); err != nil {
+		return nil, err
+	}
+	plugins := make([]string, 0, len(raw))
+	for _, plugin := range raw {
+		// every plugin config must at least be an object naming the plugin
+		var config struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(plugin, &config); err != nil {
+			return nil, err
+		}
+		if config.Type == "" {
+			return nil, errors.Errorf("plugin config without a type: %s", plugin)
+		}
+		if _, err := os.Stat(filepath.Join(binDir, config.Type)); err != nil {
+			klog.Errorf("Ignoring extra CNI plugin %q, it is not installed in %s", config.Type, binDir)
+			continue
+		}
+		plugins = append(plugins, string(plugin))
 	}
+	return plugins, nil
 }
 
 // cniConfigPath is where kindnetd will write the computed CNI config
 const cniConfigPath = "/etc/cni/net.d/10-kindnet.conflist"
 
+// cniExtraPluginsPath is where the optional kindnet-cni-plugins ConfigMap is
+// mounted, it should be a JSON list of plugin configs
+const cniExtraPluginsPath = "/etc/kindnet/cni-plugins/plugins.json"
+
+// cniBinDir is where the node's CNI plugin binaries are mounted
+const cniBinDir = "/opt/cni/bin"
+
 const cniConfigTemplate = `
 {
 	"cniVersion": "0.3.1",
@@ -60,20 +154,27 @@ const cniConfigTemplate = `
 	{
 		"type": "ptp",
 		"ipMasq": false,
+		{{- if .MTU }}
+		"mtu": {{ .MTU }},
+		{{- end }}
 		"ipam": {
 			"type": "host-local",
 			"dataDir": "/run/cni-ipam-state",
 			"routes": [
+				{{- range $i, $route := .DefaultRoutes }}{{ if $i }},{{ end }}
 				{
-					"dst": "{{ .DefaultRoute }}"
+					"dst": "{{ $route }}"
 				}
+				{{- end }}
 			],
 			"ranges": [
+			{{- range $i, $cidr := .PodCIDRs }}{{ if $i }},{{ end }}
 			[
 				{
-					"subnet": "{{ .PodCIDR }}"
+					"subnet": "{{ $cidr }}"
 				}
 			]
+			{{- end }}
 		]
 		}
 	},
@@ -83,6 +184,9 @@ const cniConfigTemplate = `
 			"portMappings": true
 		}
 	}
+	{{- range .ExtraPlugins }},
+	{{ . }}
+	{{- end }}
 	]
 }
 `
@@ -124,6 +228,7 @@ func (c *CNIConfigWriter) Write(inputs CNIConfigInputs) error {
 
 	// we're safely done now, record the inputs
 	c.lastInputs = inputs
+	cniConfigGeneration.Inc()
 	return nil
 }
 
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadExtraPlugins(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kindnetd-cni")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	binDir := filepath.Join(dir, "bin")
+	if err := os.Mkdir(binDir, 0755); err != nil {
+		t.Fatalf("unexpected error creating bin dir: %v", err)
+	}
+	for _, plugin := range []string{"bandwidth", "tuning"} {
+		if err := ioutil.WriteFile(filepath.Join(binDir, plugin), nil, 0755); err != nil {
+			t.Fatalf("unexpected error creating plugin: %v", err)
+		}
+	}
+	cases := []struct {
+		Name        string
+		Contents    *string
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name: "no file",
+		},
+		{
+			Name:     "installed plugins",
+			Contents: stringPtr(`[{"type": "bandwidth", "capabilities": {"bandwidth": true}}, {"type": "tuning"}]`),
+			Expected: []string{`{"type": "bandwidth", "capabilities": {"bandwidth": true}}`, `{"type": "tuning"}`},
+		},
+		{
+			Name:     "plugins that are not installed are skipped",
+			Contents: stringPtr(`[{"type": "firewall"}, {"type": "tuning"}]`),
+			Expected: []string{`{"type": "tuning"}`},
+		},
+		{
+			Name:        "not a list",
+			Contents:    stringPtr(`{"type": "bandwidth"}`),
+			ExpectError: true,
+		},
+		{
+			Name:        "plugin without a type",
+			Contents:    stringPtr(`[{"capabilities": {"bandwidth": true}}]`),
+			ExpectError: true,
+		},
+	}
+	// NOTE: not parallel, the temp dir is removed when the test returns
+	for i, tc := range cases {
+		i, tc := i, tc // capture range variables
+		t.Run(tc.Name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("plugins-%d.json", i))
+			if tc.Contents != nil {
+				if err := ioutil.WriteFile(path, []byte(*tc.Contents), 0644); err != nil {
+					t.Fatalf("unexpected error writing plugins: %v", err)
+				}
+			}
+			result, err := readExtraPlugins(path, binDir)
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.ExpectError && !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
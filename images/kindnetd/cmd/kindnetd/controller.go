@@ -0,0 +1,292 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	"k8s.io/utils/net"
+)
+
+// NodeController reconciles the routes to the other nodes' PodCIDRs and this
+// node's CNI config from node events
+type NodeController struct {
+	nodeLister  corelisters.NodeLister
+	nodesSynced cache.InformerSynced
+	queue       workqueue.RateLimitingInterface
+	// synced is set to 1 once the node cache has synced
+	synced int32
+
+	hostIP     string
+	podSubnets []string
+	cniConfig  *CNIConfigWriter
+
+	// routes programmed for each node, keyed by node name
+	// NOTE: only accessed from the single worker goroutine
+	routes map[string][]nodeRoute
+	// currentNode is the name of the node kindnetd is running on, once known
+	currentNode atomic.Value
+}
+
+// nodeRoute is a route to a node's PodCIDR via the node's IP
+type nodeRoute struct {
+	podCIDR string
+	nodeIP  string
+}
+
+// NewNodeController returns a new NodeController for the node informer
+// hostIP is the IP of the node kindnetd is running on, podSubnets are the
+// cluster's pod subnets, used to clean up routes left by previous runs
+func NewNodeController(nodeInformer coreinformers.NodeInformer, cniConfig *CNIConfigWriter, hostIP string, podSubnets []string) *NodeController {
+	c := &NodeController{
+		nodeLister:  nodeInformer.Lister(),
+		nodesSynced: nodeInformer.Informer().HasSynced,
+		queue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "nodes"),
+		hostIP:      hostIP,
+		podSubnets:  podSubnets,
+		cniConfig:   cniConfig,
+		routes:      map[string][]nodeRoute{},
+	}
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			c.enqueue(new)
+		},
+		DeleteFunc: c.enqueue,
+	})
+	return c
+}
+
+func (c *NodeController) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// Run waits for the node cache to sync, removes routes to nodes that no
+// longer exist and then processes node events until stopCh is closed
+func (c *NodeController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Waiting for node informer caches to sync")
+	if !cache.WaitForCacheSync(stopCh, c.nodesSynced) {
+		return fmt.Errorf("failed to wait for node caches to sync")
+	}
+	atomic.StoreInt32(&c.synced, 1)
+
+	// routes left behind by a previous kindnetd for deleted nodes will never
+	// get a delete event, so clean them up once up front
+	if err := wait.ExponentialBackoff(wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Steps:    5,
+	}, func() (bool, error) {
+		if err := c.removeStaleRoutes(); err != nil {
+			klog.Errorf("Failed to remove stale routes, retrying: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		klog.Errorf("Giving up removing stale routes: %v", err)
+	}
+
+	// a single worker, the routes are not safe for concurrent use
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+// WatchExtraPlugins re-syncs this node's CNI config when the extra CNI
+// plugins file at path changes, checking every interval until stopCh is closed
+func (c *NodeController) WatchExtraPlugins(path string, interval time.Duration, stopCh <-chan struct{}) {
+	// the file is read when this node is first synced, so only changes
+	// from here on need a re-sync
+	last, _ := ioutil.ReadFile(path)
+	wait.Until(func() {
+		contents, _ := ioutil.ReadFile(path)
+		if bytes.Equal(contents, last) {
+			return
+		}
+		last = contents
+		if name, ok := c.currentNode.Load().(string); ok {
+			klog.Infof("%s changed, updating the CNI config", path)
+			c.queue.Add(name)
+		}
+	}, interval, stopCh)
+}
+
+// HasSynced returns true once the controller has synced the node cache
+func (c *NodeController) HasSynced() bool {
+	return atomic.LoadInt32(&c.synced) == 1
+}
+
+func (c *NodeController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *NodeController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	start := time.Now()
+	err := c.syncNode(key.(string))
+	observeReconcile("node", start, err)
+	if err != nil {
+		// retry with backoff
+		klog.Errorf("Failed to sync node %s, retrying: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// syncNode reconciles the routes to the node named name, or this node's CNI
+// config if name is the current node
+func (c *NodeController) syncNode(name string) error {
+	node, err := c.nodeLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		// the node is gone, so are its pods
+		klog.Infof("Node %s was deleted, removing routes\n", name)
+		if err := c.setRoutes(name, nil); err != nil {
+			return err
+		}
+		delete(c.routes, name)
+		c.updateRoutesMetric()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// first get this node's IPs
+	nodeIPs := internalIPs(*node)
+	if len(nodeIPs) == 0 {
+		klog.Infof("Node %v has no Internal IP, ignoring\n", node.Name)
+		return nil
+	}
+
+	// This is our node. We don't need to add routes, but we might need to
+	// update the cni config.
+	if containsString(nodeIPs, c.hostIP) {
+		klog.Infof("handling current node\n")
+		c.currentNode.Store(node.Name)
+		inputs, err := ComputeCNIConfigInputs(*node, c.hostIP)
+		if err != nil {
+			return err
+		}
+		return c.cniConfig.Write(inputs)
+	}
+
+	klog.Infof("Handling node with IPs: %v\n", nodeIPs)
+	return c.setRoutes(node.Name, routesForNode(node, nodeIPs))
+}
+
+// setRoutes ensures the routes to node are exactly routes, removing any
+// previously programmed routes that are no longer wanted
+func (c *NodeController) setRoutes(node string, routes []nodeRoute) error {
+	for _, old := range c.routes[node] {
+		if containsRoute(routes, old) {
+			continue
+		}
+		klog.Infof("Removing stale route to %s via %s for node %s\n", old.podCIDR, old.nodeIP, node)
+		if err := deleteRoute(old.nodeIP, old.podCIDR); err != nil {
+			return err
+		}
+	}
+	// record the routes before adding them, so that they are still cleaned
+	// up if adding only partially succeeds and the node then changes
+	c.routes[node] = routes
+	c.updateRoutesMetric()
+	for _, r := range routes {
+		if err := syncRoute(r.nodeIP, r.podCIDR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRoutesMetric records the number of programmed routes
+func (c *NodeController) updateRoutesMetric() {
+	count := 0
+	for _, routes := range c.routes {
+		count += len(routes)
+	}
+	routesProgrammed.Set(float64(count))
+}
+
+// removeStaleRoutes deletes routes within the cluster's pod subnets that do
+// not route to the PodCIDR of a current node
+func (c *NodeController) removeStaleRoutes() error {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	wanted := []nodeRoute{}
+	for _, node := range nodes {
+		wanted = append(wanted, routesForNode(node, internalIPs(*node))...)
+	}
+	return deleteRoutesExcept(c.podSubnets, wanted)
+}
+
+// routesForNode returns the routes to each of the node's PodCIDRs via its
+// IP of the same family
+func routesForNode(node *corev1.Node, nodeIPs []string) []nodeRoute {
+	routes := []nodeRoute{}
+	for _, podCIDR := range nodePodCIDRs(*node) {
+		klog.Infof("Node %v has CIDR %s \n", node.Name, podCIDR)
+		nodeIP := ipForFamily(nodeIPs, net.IsIPv6CIDRString(podCIDR))
+		if nodeIP == "" {
+			klog.Infof("Node %v has no Internal IP for CIDR %s, ignoring\n", node.Name, podCIDR)
+			continue
+		}
+		routes = append(routes, nodeRoute{podCIDR: podCIDR, nodeIP: nodeIP})
+	}
+	return routes
+}
+
+func containsRoute(routes []nodeRoute, r nodeRoute) bool {
+	for _, route := range routes {
+		if route == r {
+			return true
+		}
+	}
+	return false
+}
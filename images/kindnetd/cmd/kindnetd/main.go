@@ -20,10 +20,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
@@ -32,16 +33,29 @@ import (
 
 // kindnetd is a simple networking daemon to complete kind's CNI implementation
 // kindnetd will ensure routes to the other node's PodCIDR via their InternalIP
+// kindnetd will remove routes to nodes that are deleted or change PodCIDR
 // kindnetd will ensure pod to pod communication will not be masquerade
 // kindnetd will also write a templated cni config supplied with PodCIDR
+// kindnetd can optionally enforce NetworkPolicies for the pods on the node
+// kindnetd serves /healthz, /readyz and /metrics on metricsPort of the node IP
 //
 // input envs:
 // - HOST_IP: should be populated by downward API
 // - POD_IP: should be populated by downward API
 // - CNI_CONFIG_TEMPLATE: the cni .conflist template, run with {{ .PodCIDR }}
+// - POD_SUBNET: the cluster's pod subnet, comma separated for dual stack
+// - NETWORK_POLICY: if "true", NetworkPolicies are enforced
 
 // TODO: improve logging & error handling
 
+// resyncPeriod is how often all nodes are reconciled, even without changes,
+// to restore routes that were removed by something else
+const resyncPeriod = 30 * time.Second
+
+// extraPluginsCheckPeriod is how often the extra CNI plugins are checked for
+// changes to the kindnet-cni-plugins ConfigMap
+const extraPluginsCheckPeriod = 5 * time.Second
+
 func main() {
 	// enable logging
 	klog.InitFlags(nil)
@@ -74,116 +88,116 @@ func main() {
 		path: cniConfigPath,
 	}
 
-	// enforce ip masquerade rules
-	// TODO: dual stack...?
-	masqAgent, err := NewIPMasqAgent(net.IsIPv6String(hostIP), []string{os.Getenv("POD_SUBNET")})
-	if err != nil {
-		panic(err.Error())
-	}
-	// run the masqAgent and panic if is not able to install the rules to no masquerade the pod to pod traffic
+	// serve health checks and metrics
+	// kindnetd is live as long as it serves these, the checks only mark it
+	// as not ready, so that transient failures do not restart it
+	live, ready := NewHealthChecks(), NewHealthChecks()
 	go func() {
-		if err := masqAgent.SyncRulesForever(time.Second * 60); err != nil {
-			panic(err.Error())
+		if err := serveMetrics(hostIP, live, ready); err != nil {
+			klog.Fatalf("failed to serve metrics: %v", err)
 		}
 	}()
 
-	// setup nodes reconcile function, closes over arguments
-	reconcileNodes := makeNodesReconciler(cniConfigWriter, hostIP)
-
-	// main control loop
-	for {
-		// Gets the Nodes information from the API
-		// TODO: use a proper controller instead
-		var nodes *corev1.NodeList
-		var err error
-		for i := 0; i < 5; i++ {
-			nodes, err = clientset.CoreV1().Nodes().List(metav1.ListOptions{})
-			if err == nil {
-				break
-			}
-			klog.Infof("Failed to get nodes, retrying after error: %v", err)
-			time.Sleep(time.Second * time.Duration(i))
+	// enforce ip masquerade rules, for each of the pod subnet's ip families
+	podSubnets := []string{}
+	for _, cidr := range strings.Split(os.Getenv("POD_SUBNET"), ",") {
+		// POD_SUBNET may be unset, or have stray separators
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			podSubnets = append(podSubnets, cidr)
 		}
-		if err != nil {
-			panic("Reached maximum retries obtaining node list: " + err.Error())
+	}
+	podSubnetsV4, podSubnetsV6 := []string{}, []string{}
+	for _, cidr := range podSubnets {
+		if net.IsIPv6CIDRString(cidr) {
+			podSubnetsV6 = append(podSubnetsV6, cidr)
+		} else {
+			podSubnetsV4 = append(podSubnetsV4, cidr)
 		}
-
-		// reconcile the nodes with retries
-		for i := 0; i < 5; i++ {
-			err = reconcileNodes(nodes)
-			if err == nil {
-				break
-			}
-			klog.Infof("Failed to reconcile routes, retrying after error: %v", err)
-			time.Sleep(time.Second * time.Duration(i))
+	}
+	for _, family := range []struct {
+		ipv6    bool
+		subnets []string
+	}{
+		{false, podSubnetsV4},
+		{true, podSubnetsV6},
+	} {
+		if len(family.subnets) == 0 {
+			continue
 		}
+		masqAgent, err := NewIPMasqAgent(family.ipv6, family.subnets)
 		if err != nil {
-			panic("Maximum retries reconciling node routes: " + err.Error())
+			panic(err.Error())
 		}
-
-		// rate limit
-		time.Sleep(10 * time.Second)
+		ready.Add("masq-agent-"+masqAgent.Family(), masqAgent.Ready)
+		// run the masqAgent and panic if is not able to install the rules to no masquerade the pod to pod traffic
+		go func() {
+			if err := masqAgent.SyncRulesForever(time.Second * 60); err != nil {
+				panic(err.Error())
+			}
+		}()
 	}
-}
-
-// nodeNodesReconciler returns a reconciliation func for nodes
-func makeNodesReconciler(cniConfig *CNIConfigWriter, hostIP string) func(*corev1.NodeList) error {
-	// reconciles a node
-	reconcileNode := func(node corev1.Node) error {
-		// first get this node's IP
-		nodeIP := internalIP(node)
-		if nodeIP == "" {
-			klog.Infof("Node %v has no Internal IP, ignoring\n", node.Name)
-			return nil
-		}
 
-		// don't do anything unless there is a PodCIDR
-		podCIDR := node.Spec.PodCIDR
-		if podCIDR == "" {
-			klog.Infof("Node %v has no CIDR, ignoring\n", node.Name)
-			return nil
+	// reconcile routes and the cni config from node events
+	stopCh := make(chan struct{})
+	informerFactory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
+	nodeController := NewNodeController(informerFactory.Core().V1().Nodes(), cniConfigWriter, hostIP, podSubnets)
+	ready.Add("node-controller", nodeController.HasSynced)
+	go nodeController.WatchExtraPlugins(cniExtraPluginsPath, extraPluginsCheckPeriod, stopCh)
+	if os.Getenv("NETWORK_POLICY") == "true" {
+		policyController, err := NewNetworkPolicyController(informerFactory, hostIP, podSubnets)
+		if err != nil {
+			panic(err.Error())
 		}
-
-		// This is our node. We don't need to add routes, but we might need to
-		// update the cni config.
-		if nodeIP == hostIP {
-			klog.Infof("handling current node\n")
-			// compute the current cni config inputs
-			if err := cniConfig.Write(
-				ComputeCNIConfigInputs(node),
-			); err != nil {
-				return err
+		ready.Add("network-policy-controller", policyController.HasSynced)
+		go func() {
+			if err := policyController.Run(stopCh); err != nil {
+				klog.Fatalf("network policy controller failed: %v", err)
 			}
-			// we're done handling this node
-			return nil
-		}
+		}()
+	}
+	informerFactory.Start(stopCh)
+	if err := nodeController.Run(stopCh); err != nil {
+		klog.Fatalf("node controller failed: %v", err)
+	}
+}
 
-		klog.Infof("Handling node with IP: %s\n", nodeIP)
-		klog.Infof("Node %v has CIDR %s \n", node.Name, podCIDR)
-		if err := syncRoute(nodeIP, podCIDR); err != nil {
-			return err
+// internalIPs returns the internalIP addresses for node
+func internalIPs(node corev1.Node) []string {
+	ips := []string{}
+	for _, address := range node.Status.Addresses {
+		if address.Type == "InternalIP" {
+			ips = append(ips, address.Address)
 		}
+	}
+	return ips
+}
 
-		return nil
+// nodePodCIDRs returns the PodCIDRs for node, one per family for dual stack
+func nodePodCIDRs(node corev1.Node) []string {
+	if len(node.Spec.PodCIDRs) > 0 {
+		return node.Spec.PodCIDRs
+	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
 	}
+	return nil
+}
 
-	// return a reconciler for all the nodes
-	return func(nodes *corev1.NodeList) error {
-		for _, node := range nodes.Items {
-			if err := reconcileNode(node); err != nil {
-				return err
-			}
+// ipForFamily returns the first of ips in the IPv6 or IPv4 family
+func ipForFamily(ips []string, ipv6 bool) string {
+	for _, ip := range ips {
+		if net.IsIPv6String(ip) == ipv6 {
+			return ip
 		}
-		return nil
 	}
+	return ""
 }
 
-// internalIP returns the internalIP address for node
-func internalIP(node corev1.Node) string {
-	for _, address := range node.Status.Addresses {
-		if address.Type == "InternalIP" {
-			return address.Address
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
 		}
 	}
-	return ""
+	return false
 }
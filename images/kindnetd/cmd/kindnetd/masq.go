@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/go-iptables/iptables"
@@ -49,6 +50,30 @@ type IPMasqAgent struct {
 	iptables          *iptables.IPTables
 	masqChain         string
 	noMasqueradeCIDRs []string
+	// synced is 1 once a sync has succeeded
+	synced int32
+	// failures is the number of consecutive failed syncs
+	failures int32
+}
+
+// maxMasqSyncFailures is how many consecutive syncs must fail before the
+// agent is no longer ready, single failures are often transient, e.g. when
+// the xtables lock is held by kube-proxy
+const maxMasqSyncFailures = 3
+
+// Family returns the IP family of the agent's rules, ipv4 or ipv6
+func (ma *IPMasqAgent) Family() string {
+	if ma.iptables.Proto() == iptables.ProtocolIPv6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// Ready returns true once the rules have synced, unless the last
+// maxMasqSyncFailures syncs failed
+func (ma *IPMasqAgent) Ready() bool {
+	return atomic.LoadInt32(&ma.synced) == 1 &&
+		atomic.LoadInt32(&ma.failures) < maxMasqSyncFailures
 }
 
 // SyncRulesForever syncs ip masquerade rules forever
@@ -56,15 +81,16 @@ type IPMasqAgent struct {
 // not deleted by an external program. It fails if can't sync the rules during 3 iterations
 // TODO: aggregate errors
 func (ma *IPMasqAgent) SyncRulesForever(interval time.Duration) error {
-	errs := 0
 	for {
 		if err := ma.SyncRules(); err != nil {
-			errs++
-			if errs > 3 {
+			masqSyncsTotal.WithLabelValues(ma.Family(), "error").Inc()
+			if errs := atomic.AddInt32(&ma.failures, 1); errs > 3 {
 				return fmt.Errorf("Can't synchronize rules after 3 attempts: %v", err)
 			}
 		} else {
-			errs = 0
+			masqSyncsTotal.WithLabelValues(ma.Family(), "success").Inc()
+			atomic.StoreInt32(&ma.synced, 1)
+			atomic.StoreInt32(&ma.failures, 0)
 		}
 		time.Sleep(interval)
 	}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestIPMasqAgentReady(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Synced   int32
+		Failures int32
+		Expected bool
+	}{
+		{
+			Name: "not synced yet",
+		},
+		{
+			Name:     "not synced yet after a failure",
+			Failures: 1,
+		},
+		{
+			Name:     "synced",
+			Synced:   1,
+			Expected: true,
+		},
+		{
+			Name:     "transient failure",
+			Synced:   1,
+			Failures: maxMasqSyncFailures - 1,
+			Expected: true,
+		},
+		{
+			Name:     "repeated failures",
+			Synced:   1,
+			Failures: maxMasqSyncFailures,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ma := &IPMasqAgent{synced: tc.Synced, failures: tc.Failures}
+			if ready := ma.Ready(); ready != tc.Expected {
+				t.Errorf("expected ready %v but got %v", tc.Expected, ready)
+			}
+		})
+	}
+}
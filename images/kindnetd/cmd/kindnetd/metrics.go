@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+/* metrics and health checks */
+
+// metricsPort is the port kindnetd serves /healthz, /readyz and /metrics on
+// kindnetd uses the host network, so this is a port on the node, which is
+// only bound on the node's IP
+const metricsPort = "19080"
+
+var (
+	reconcilesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kindnetd",
+			Name:      "reconciles_total",
+			Help:      "Number of reconciles by controller.",
+		},
+		[]string{"controller"},
+	)
+	reconcileErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kindnetd",
+			Name:      "reconcile_errors_total",
+			Help:      "Number of failed reconciles by controller.",
+		},
+		[]string{"controller"},
+	)
+	reconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "kindnetd",
+			Name:      "reconcile_duration_seconds",
+			Help:      "Latency of reconciles by controller.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"controller"},
+	)
+	routesProgrammed = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "kindnetd",
+			Name:      "routes",
+			Help:      "Number of routes programmed to other nodes' PodCIDRs.",
+		},
+	)
+	masqSyncsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kindnetd",
+			Name:      "masquerade_syncs_total",
+			Help:      "Number of ip masquerade rule syncs by IP family and result.",
+		},
+		[]string{"family", "result"},
+	)
+	cniConfigGeneration = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "kindnetd",
+			Name:      "cni_config_generation",
+			Help:      "Number of times the CNI config has been written.",
+		},
+	)
+)
+
+// observeReconcile records the metrics for a reconcile by controller that
+// started at start and returned err
+func observeReconcile(controller string, start time.Time, err error) {
+	reconcilesTotal.WithLabelValues(controller).Inc()
+	reconcileDuration.WithLabelValues(controller).Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileErrorsTotal.WithLabelValues(controller).Inc()
+	}
+}
+
+// HealthChecks tracks named checks, which pass once all of the checks pass
+type HealthChecks struct {
+	mu     sync.Mutex
+	checks map[string]func() bool
+}
+
+// NewHealthChecks returns HealthChecks without any checks
+func NewHealthChecks() *HealthChecks {
+	return &HealthChecks{checks: map[string]func() bool{}}
+}
+
+// Add adds the check name, which passes when check returns true
+func (h *HealthChecks) Add(name string, check func() bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// failing returns the sorted names of the failing checks
+func (h *HealthChecks) failing() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	failing := []string{}
+	for name, check := range h.checks {
+		if !check() {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(failing)
+	return failing
+}
+
+// ServeHTTP implements http.Handler for /healthz and /readyz
+func (h *HealthChecks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if failing := h.failing(); len(failing) > 0 {
+		http.Error(w, fmt.Sprintf("failing checks: %s", strings.Join(failing, ", ")), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprint(w, "ok")
+}
+
+// serveMetrics serves liveness checks on /healthz, readiness checks on
+// /readyz and /metrics on metricsPort of hostIP
+func serveMetrics(hostIP string, live, ready *HealthChecks) error {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		reconcilesTotal,
+		reconcileErrorsTotal,
+		reconcileDuration,
+		routesProgrammed,
+		masqSyncsTotal,
+		cniConfigGeneration,
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", live)
+	mux.Handle("/readyz", ready)
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return http.ListenAndServe(net.JoinHostPort(hostIP, metricsPort), mux)
+}
@@ -0,0 +1,624 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/go-iptables/iptables"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	utilnet "k8s.io/utils/net"
+)
+
+// names of the filter chains for network policy rules
+const (
+	networkPolicyChainName = "KIND-NETWORK-POLICY"
+	policyIngressChainName = "KIND-NP-INGRESS"
+	policyEgressChainName  = "KIND-NP-EGRESS"
+)
+
+// the policy rules are always computed for all pods, policies and namespaces
+// so all events share a single work queue key
+const networkPolicySyncKey = "sync"
+
+// NetworkPolicyController enforces NetworkPolicies for the pods on this node
+// by programming iptables filter rules, alongside the IPMasqAgent
+//
+// Traffic between pods is forwarded by the node, so ingress rules for the
+// pods on this node and egress rules from the pods on this node are enforced
+// in the FORWARD chain. Policies do not apply to host network pods.
+type NetworkPolicyController struct {
+	podLister       corelisters.PodLister
+	namespaceLister corelisters.NamespaceLister
+	policyLister    networkinglisters.NetworkPolicyLister
+	cachesSynced    []cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	// synced is set to 1 once the caches have synced
+	synced int32
+
+	hostIP   string
+	iptables []*iptables.IPTables
+}
+
+// NewNetworkPolicyController returns a new NetworkPolicyController using
+// informers from informerFactory, for the pods running on the node with
+// hostIP. Rules are programmed for each of the IP families in podSubnets.
+func NewNetworkPolicyController(informerFactory informers.SharedInformerFactory, hostIP string, podSubnets []string) (*NetworkPolicyController, error) {
+	podInformer := informerFactory.Core().V1().Pods()
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	policyInformer := informerFactory.Networking().V1().NetworkPolicies()
+
+	c := &NetworkPolicyController{
+		podLister:       podInformer.Lister(),
+		namespaceLister: namespaceInformer.Lister(),
+		policyLister:    policyInformer.Lister(),
+		cachesSynced: []cache.InformerSynced{
+			podInformer.Informer().HasSynced,
+			namespaceInformer.Informer().HasSynced,
+			policyInformer.Informer().HasSynced,
+		},
+		queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "networkpolicies"),
+		hostIP: hostIP,
+	}
+
+	// program rules for each ip family in use
+	hasIPv4, hasIPv6 := false, false
+	for _, cidr := range podSubnets {
+		if utilnet.IsIPv6CIDRString(cidr) {
+			hasIPv6 = true
+		} else {
+			hasIPv4 = true
+		}
+	}
+	for _, family := range []struct {
+		enabled  bool
+		protocol iptables.Protocol
+	}{
+		{hasIPv4, iptables.ProtocolIPv4},
+		{hasIPv6, iptables.ProtocolIPv6},
+	} {
+		if !family.enabled {
+			continue
+		}
+		ipt, err := iptables.NewWithProtocol(family.protocol)
+		if err != nil {
+			return nil, err
+		}
+		c.iptables = append(c.iptables, ipt)
+	}
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.queue.Add(networkPolicySyncKey)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			c.queue.Add(networkPolicySyncKey)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.queue.Add(networkPolicySyncKey)
+		},
+	}
+	podInformer.Informer().AddEventHandler(handler)
+	namespaceInformer.Informer().AddEventHandler(handler)
+	policyInformer.Informer().AddEventHandler(handler)
+	return c, nil
+}
+
+// Run waits for the caches to sync and then programs the network policy
+// rules until stopCh is closed
+func (c *NetworkPolicyController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Waiting for network policy informer caches to sync")
+	if !cache.WaitForCacheSync(stopCh, c.cachesSynced...) {
+		return fmt.Errorf("failed to wait for network policy caches to sync")
+	}
+	atomic.StoreInt32(&c.synced, 1)
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+// HasSynced returns true once the controller has synced its caches
+func (c *NetworkPolicyController) HasSynced() bool {
+	return atomic.LoadInt32(&c.synced) == 1
+}
+
+func (c *NetworkPolicyController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *NetworkPolicyController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	start := time.Now()
+	err := c.syncRules()
+	observeReconcile("networkpolicy", start, err)
+	if err != nil {
+		// retry with backoff
+		klog.Errorf("Failed to sync network policy rules, retrying: %v", err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// syncRules recomputes and programs the network policy rules for all families
+func (c *NetworkPolicyController) syncRules() error {
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	policies, err := c.policyLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, ipt := range c.iptables {
+		ipv6 := ipt.Proto() == iptables.ProtocolIPv6
+		ingress, egress := policyRules(c.hostIP, ipv6, pods, namespaces, policies)
+		if err := syncPolicyChains(ipt, ingress, egress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPolicyChains replaces the rules in the ingress and egress chains and
+// makes sure the FORWARD chain sends traffic through them
+func syncPolicyChains(ipt *iptables.IPTables, ingress, egress [][]string) error {
+	// replace both chains in a single iptables-restore transaction, so
+	// policies are enforced throughout every sync
+	// NOTE: with --noflush only the chains declared in the input are
+	// flushed, and they are created if they do not exist
+	restore := "iptables-restore"
+	if ipt.Proto() == iptables.ProtocolIPv6 {
+		restore = "ip6tables-restore"
+	}
+	cmd := exec.Command(restore, "--noflush", "--wait")
+	cmd.Stdin = bytes.NewReader(policyChainsRestoreInput(ingress, egress))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to replace network policy chains: %v: %s", err, out)
+	}
+
+	// the top level chain only jumps to the ingress and egress chains
+	if err := ensureChain(ipt, networkPolicyChainName); err != nil {
+		return err
+	}
+	for _, chain := range []string{policyIngressChainName, policyEgressChainName} {
+		if err := ipt.AppendUnique("filter", networkPolicyChainName, "-j", chain); err != nil {
+			return err
+		}
+	}
+
+	// policy must be checked before anything else in FORWARD accepts traffic
+	jump := []string{"-j", networkPolicyChainName, "-m", "comment", "--comment", "kindnetd: enforce network policies for pod traffic"}
+	exists, err := ipt.Exists("filter", "FORWARD", jump...)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ipt.Insert("filter", "FORWARD", 1, jump...)
+	}
+	return nil
+}
+
+// policyChainsRestoreInput returns the iptables-restore input replacing the
+// ingress and egress chains with rules
+func policyChainsRestoreInput(ingress, egress [][]string) []byte {
+	var b bytes.Buffer
+	b.WriteString("*filter\n")
+	b.WriteString(":" + policyIngressChainName + " - [0:0]\n")
+	b.WriteString(":" + policyEgressChainName + " - [0:0]\n")
+	for _, chain := range []struct {
+		name  string
+		rules [][]string
+	}{
+		{policyIngressChainName, ingress},
+		{policyEgressChainName, egress},
+	} {
+		// replies to allowed connections are always allowed
+		rules := append([][]string{{"-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "RETURN"}}, chain.rules...)
+		for _, rule := range rules {
+			b.WriteString("-A " + chain.name)
+			for _, arg := range rule {
+				b.WriteString(" " + restoreQuote(arg))
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("COMMIT\n")
+	return b.Bytes()
+}
+
+// restoreQuote quotes arg for iptables-restore if it contains whitespace
+func restoreQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	return `"` + strings.Replace(arg, `"`, `\"`, -1) + `"`
+}
+
+// ensureChain creates chain in the filter table if it does not exist
+func ensureChain(ipt *iptables.IPTables, chain string) error {
+	chains, err := ipt.ListChains("filter")
+	if err != nil {
+		return fmt.Errorf("failed to list chains: %v", err)
+	}
+	if containsString(chains, chain) {
+		return nil
+	}
+	return ipt.NewChain("filter", chain)
+}
+
+// policyRules returns the ingress and egress iptables rules enforcing
+// policies for the pods on the node with hostIP, for the IPv6 or IPv4 family
+//
+// For each isolated pod the rules RETURN the allowed traffic and DROP the
+// rest, traffic for pods that are not isolated falls through the chain
+func policyRules(hostIP string, ipv6 bool, pods []*corev1.Pod, namespaces []*corev1.Namespace, policies []*networkingv1.NetworkPolicy) (ingress, egress [][]string) {
+	// only pods with IPs take part in network policy
+	policyPods := []*corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if podIP(pod, ipv6) == "" {
+			continue
+		}
+		policyPods = append(policyPods, pod)
+	}
+	namespaceLabels := map[string]labels.Set{}
+	for _, namespace := range namespaces {
+		namespaceLabels[namespace.Name] = labels.Set(namespace.Labels)
+	}
+
+	// sort for stable rules
+	sort.Slice(policyPods, func(i, j int) bool {
+		return policyPods[i].Namespace+"/"+policyPods[i].Name < policyPods[j].Namespace+"/"+policyPods[j].Name
+	})
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Namespace+"/"+policies[i].Name < policies[j].Namespace+"/"+policies[j].Name
+	})
+
+	for _, pod := range policyPods {
+		if pod.Status.HostIP != hostIP {
+			continue
+		}
+		ip := podIP(pod, ipv6)
+		ingressIsolated, egressIsolated := false, false
+		ingressRules, egressRules := newRuleSet(), newRuleSet()
+		for _, policy := range policies {
+			if !policySelectsPod(policy, pod) {
+				continue
+			}
+			if policyHasType(policy, networkingv1.PolicyTypeIngress) {
+				ingressIsolated = true
+				for _, rule := range policy.Spec.Ingress {
+					for _, peer := range resolvePeers(rule.From, policy.Namespace, ipv6, policyPods, namespaceLabels) {
+						// named ports are resolved against the destination pod
+						for _, ports := range portArgs(rule.Ports, pod) {
+							ingressRules.add(append(append([]string{"-d", ip}, peer.args("-s")...), ports...))
+						}
+					}
+				}
+			}
+			if policyHasType(policy, networkingv1.PolicyTypeEgress) {
+				egressIsolated = true
+				for _, rule := range policy.Spec.Egress {
+					for _, peer := range resolvePeers(rule.To, policy.Namespace, ipv6, policyPods, namespaceLabels) {
+						for _, ports := range portArgs(rule.Ports, peer.pod) {
+							egressRules.add(append(append([]string{"-s", ip}, peer.args("-d")...), ports...))
+						}
+					}
+				}
+			}
+		}
+
+		podName := pod.Namespace + "/" + pod.Name
+		if ingressIsolated {
+			ingress = append(ingress, ingressRules.returnRules()...)
+			ingress = append(ingress, []string{"-d", ip, "-j", "DROP", "-m", "comment", "--comment", "kindnetd: " + podName + " is isolated for ingress"})
+		}
+		if egressIsolated {
+			egress = append(egress, egressRules.returnRules()...)
+			egress = append(egress, []string{"-s", ip, "-j", "DROP", "-m", "comment", "--comment", "kindnetd: " + podName + " is isolated for egress"})
+		}
+	}
+	return ingress, egress
+}
+
+// ruleSet is an ordered set of iptables rule matches
+type ruleSet struct {
+	seen  map[string]bool
+	rules [][]string
+}
+
+func newRuleSet() *ruleSet {
+	return &ruleSet{seen: map[string]bool{}}
+}
+
+func (r *ruleSet) add(rule []string) {
+	key := strings.Join(rule, " ")
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+	r.rules = append(r.rules, rule)
+}
+
+// returnRules returns the rules in the set with a RETURN target
+func (r *ruleSet) returnRules() [][]string {
+	rules := make([][]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, append(append([]string{}, rule...), "-j", "RETURN"))
+	}
+	return rules
+}
+
+// policyPeer is a resolved NetworkPolicyPeer, either a pod, a CIDR or any
+type policyPeer struct {
+	// pod is set if the peer is a pod, for resolving named ports
+	pod  *corev1.Pod
+	cidr string
+}
+
+// args returns the iptables match for the peer as a source or destination
+func (p policyPeer) args(flag string) []string {
+	if p.cidr == "" {
+		return nil
+	}
+	return []string{flag, p.cidr}
+}
+
+// resolvePeers returns the peers matched by the NetworkPolicyPeers of a rule
+// in a policy in namespace
+func resolvePeers(peers []networkingv1.NetworkPolicyPeer, namespace string, ipv6 bool, pods []*corev1.Pod, namespaceLabels map[string]labels.Set) []policyPeer {
+	// no peers allows traffic from or to anywhere
+	if len(peers) == 0 {
+		return []policyPeer{{}}
+	}
+	resolved := []policyPeer{}
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			if utilnet.IsIPv6CIDRString(peer.IPBlock.CIDR) != ipv6 {
+				continue
+			}
+			cidrs, err := cidrExcept(peer.IPBlock.CIDR, peer.IPBlock.Except)
+			if err != nil {
+				klog.Errorf("Ignoring invalid ipBlock in network policy in namespace %s: %v", namespace, err)
+				continue
+			}
+			for _, cidr := range cidrs {
+				resolved = append(resolved, policyPeer{cidr: cidr})
+			}
+			continue
+		}
+
+		podSelector, ok := selectorOrEverything(peer.PodSelector, namespace)
+		if !ok {
+			continue
+		}
+		// without a namespace selector only the policy's namespace matches
+		var namespaceSelector labels.Selector
+		if peer.NamespaceSelector != nil {
+			if namespaceSelector, ok = selectorOrEverything(peer.NamespaceSelector, namespace); !ok {
+				continue
+			}
+		}
+		for _, pod := range pods {
+			if namespaceSelector == nil && pod.Namespace != namespace {
+				continue
+			}
+			if namespaceSelector != nil && !namespaceSelector.Matches(namespaceLabels[pod.Namespace]) {
+				continue
+			}
+			if !podSelector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			resolved = append(resolved, policyPeer{pod: pod, cidr: podIP(pod, ipv6)})
+		}
+	}
+	return resolved
+}
+
+// selectorOrEverything converts selector, a nil selector matches everything
+func selectorOrEverything(selector *metav1.LabelSelector, namespace string) (labels.Selector, bool) {
+	if selector == nil {
+		return labels.Everything(), true
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("Ignoring invalid selector in network policy in namespace %s: %v", namespace, err)
+		return nil, false
+	}
+	return s, true
+}
+
+// portArgs returns the iptables matches for each of ports, resolving named
+// ports against the containers of pod
+// no ports matches all traffic, unresolvable named ports match nothing
+func portArgs(ports []networkingv1.NetworkPolicyPort, pod *corev1.Pod) [][]string {
+	if len(ports) == 0 {
+		return [][]string{nil}
+	}
+	args := [][]string{}
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		if port.Protocol != nil {
+			protocol = *port.Protocol
+		}
+		match := []string{"-p", strings.ToLower(string(protocol))}
+		if port.Port == nil {
+			args = append(args, match)
+			continue
+		}
+		number := port.Port.IntValue()
+		if port.Port.StrVal != "" {
+			number = namedPort(pod, port.Port.StrVal, protocol)
+		}
+		if number == 0 {
+			continue
+		}
+		args = append(args, append(match, "--dport", fmt.Sprint(number)))
+	}
+	return args
+}
+
+// namedPort returns the number of the container port called name in pod,
+// or 0 if there is no such port
+func namedPort(pod *corev1.Pod, name string, protocol corev1.Protocol) int {
+	if pod == nil {
+		return 0
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			portProtocol := port.Protocol
+			if portProtocol == "" {
+				portProtocol = corev1.ProtocolTCP
+			}
+			if port.Name == name && portProtocol == protocol {
+				return int(port.ContainerPort)
+			}
+		}
+	}
+	return 0
+}
+
+// policySelectsPod returns true if policy applies to pod
+func policySelectsPod(policy *networkingv1.NetworkPolicy, pod *corev1.Pod) bool {
+	if policy.Namespace != pod.Namespace {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil {
+		klog.Errorf("Ignoring network policy %s/%s with invalid pod selector: %v", policy.Namespace, policy.Name, err)
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// policyHasType returns true if policy isolates pods for policyType
+func policyHasType(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	// policies without types are ingress policies, and egress policies if
+	// they have egress rules
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return policyType == networkingv1.PolicyTypeIngress ||
+			(policyType == networkingv1.PolicyTypeEgress && len(policy.Spec.Egress) > 0)
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+// podIP returns the pod's IP in the IPv6 or IPv4 family
+func podIP(pod *corev1.Pod, ipv6 bool) string {
+	ips := []string{}
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ipForFamily(ips, ipv6)
+}
+
+// cidrExcept returns the CIDRs covering cidr without any of the excepts
+func cidrExcept(cidr string, excepts []string) ([]string, error) {
+	_, block, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	blocks := []*net.IPNet{block}
+	for _, except := range excepts {
+		_, exceptBlock, err := net.ParseCIDR(except)
+		if err != nil {
+			return nil, err
+		}
+		remaining := []*net.IPNet{}
+		for _, b := range blocks {
+			remaining = append(remaining, subtractCIDR(b, exceptBlock)...)
+		}
+		blocks = remaining
+	}
+	cidrs := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		cidrs = append(cidrs, b.String())
+	}
+	return cidrs, nil
+}
+
+// subtractCIDR returns the CIDRs covering block without except, by splitting
+// block in halves until they are either within except or do not overlap it
+func subtractCIDR(block, except *net.IPNet) []*net.IPNet {
+	blockOnes, bits := block.Mask.Size()
+	exceptOnes, exceptBits := except.Mask.Size()
+	if bits != exceptBits {
+		return []*net.IPNet{block}
+	}
+	if exceptOnes <= blockOnes {
+		if except.Contains(block.IP) {
+			return nil
+		}
+		return []*net.IPNet{block}
+	}
+	if !block.Contains(except.IP) {
+		return []*net.IPNet{block}
+	}
+	// split into the two halves of block
+	mask := net.CIDRMask(blockOnes+1, bits)
+	lower := &net.IPNet{IP: block.IP.Mask(mask), Mask: mask}
+	upperIP := make(net.IP, len(lower.IP))
+	copy(upperIP, lower.IP)
+	upperIP[blockOnes/8] |= 0x80 >> uint(blockOnes%8)
+	upper := &net.IPNet{IP: upperIP, Mask: mask}
+	return append(subtractCIDR(lower, except), subtractCIDR(upper, except)...)
+}
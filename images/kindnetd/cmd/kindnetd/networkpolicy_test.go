@@ -0,0 +1,421 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func testPod(namespace, name string, podLabels map[string]string, ips ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Ports: []corev1.ContainerPort{
+					{Name: "http", ContainerPort: 8080},
+					{Name: "dns", ContainerPort: 53, Protocol: corev1.ProtocolUDP},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{HostIP: "172.18.0.2", Phase: corev1.PodRunning},
+	}
+	for _, ip := range ips {
+		pod.Status.PodIPs = append(pod.Status.PodIPs, corev1.PodIP{IP: ip})
+	}
+	return pod
+}
+
+func TestSubtractCIDR(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Block    string
+		Except   string
+		Expected []string
+	}{
+		{
+			Name:     "no overlap",
+			Block:    "10.0.0.0/24",
+			Except:   "10.0.1.0/24",
+			Expected: []string{"10.0.0.0/24"},
+		},
+		{
+			Name:     "except covers block",
+			Block:    "10.0.0.0/24",
+			Except:   "10.0.0.0/16",
+			Expected: nil,
+		},
+		{
+			Name:     "except is a half",
+			Block:    "10.0.0.0/24",
+			Except:   "10.0.0.128/25",
+			Expected: []string{"10.0.0.0/25"},
+		},
+		{
+			Name:     "except is a single address",
+			Block:    "10.0.0.0/30",
+			Except:   "10.0.0.2/32",
+			Expected: []string{"10.0.0.0/31", "10.0.0.3/32"},
+		},
+		{
+			Name:     "IPv6",
+			Block:    "fd00::/126",
+			Except:   "fd00::1/128",
+			Expected: []string{"fd00::/128", "fd00::2/127"},
+		},
+		{
+			Name:     "mixed families",
+			Block:    "10.0.0.0/24",
+			Except:   "fd00::/64",
+			Expected: []string{"10.0.0.0/24"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			_, block, _ := net.ParseCIDR(tc.Block)
+			_, except, _ := net.ParseCIDR(tc.Except)
+			var result []string
+			for _, cidr := range subtractCIDR(block, except) {
+				result = append(result, cidr.String())
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestCIDRExcept(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		CIDR        string
+		Excepts     []string
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name:     "no excepts",
+			CIDR:     "10.0.0.0/16",
+			Expected: []string{"10.0.0.0/16"},
+		},
+		{
+			Name:     "multiple excepts",
+			CIDR:     "10.0.0.0/24",
+			Excepts:  []string{"10.0.0.0/26", "10.0.0.192/26"},
+			Expected: []string{"10.0.0.64/26", "10.0.0.128/26"},
+		},
+		{
+			Name:     "IPv6",
+			CIDR:     "fd00::/64",
+			Excepts:  []string{"fd00::8000:0:0:0/65"},
+			Expected: []string{"fd00::/65"},
+		},
+		{
+			Name:        "invalid cidr",
+			CIDR:        "10.0.0.0",
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid except",
+			CIDR:        "10.0.0.0/24",
+			Excepts:     []string{"bogus"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := cidrExcept(tc.CIDR, tc.Excepts)
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.ExpectError && !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestPortArgs(t *testing.T) {
+	t.Parallel()
+	udp := corev1.ProtocolUDP
+	sctp := corev1.ProtocolSCTP
+	port := func(p intstr.IntOrString) *intstr.IntOrString { return &p }
+	cases := []struct {
+		Name     string
+		Ports    []networkingv1.NetworkPolicyPort
+		Pod      *corev1.Pod
+		Expected [][]string
+	}{
+		{
+			Name:     "no ports",
+			Expected: [][]string{nil},
+		},
+		{
+			Name:     "protocol defaults to tcp",
+			Ports:    []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromInt(80))}},
+			Expected: [][]string{{"-p", "tcp", "--dport", "80"}},
+		},
+		{
+			Name:     "protocol only",
+			Ports:    []networkingv1.NetworkPolicyPort{{Protocol: &sctp}},
+			Expected: [][]string{{"-p", "sctp"}},
+		},
+		{
+			Name: "named ports",
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Port: port(intstr.FromString("http"))},
+				{Protocol: &udp, Port: port(intstr.FromString("dns"))},
+			},
+			Pod:      testPod("default", "web", nil, "10.244.0.5"),
+			Expected: [][]string{{"-p", "tcp", "--dport", "8080"}, {"-p", "udp", "--dport", "53"}},
+		},
+		{
+			Name: "named port with the wrong protocol",
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: port(intstr.FromString("http"))},
+			},
+			Pod:      testPod("default", "web", nil, "10.244.0.5"),
+			Expected: [][]string{},
+		},
+		{
+			Name:     "named port without a pod",
+			Ports:    []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromString("http"))}},
+			Expected: [][]string{},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := portArgs(tc.Ports, tc.Pod)
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestResolvePeers(t *testing.T) {
+	t.Parallel()
+	pods := []*corev1.Pod{
+		testPod("default", "web", map[string]string{"app": "web"}, "10.244.0.5", "fd00:10:244::5"),
+		testPod("default", "db", map[string]string{"app": "db"}, "10.244.0.6", "fd00:10:244::6"),
+		testPod("monitoring", "prometheus", map[string]string{"app": "prometheus"}, "10.244.1.7", "fd00:10:244:1::7"),
+		testPod("other", "web", map[string]string{"app": "web"}, "10.244.1.8", "fd00:10:244:1::8"),
+	}
+	namespaceLabels := map[string]labels.Set{
+		"default":    {},
+		"monitoring": {"team": "monitoring"},
+		"other":      {"team": "other"},
+	}
+	cases := []struct {
+		Name     string
+		Peers    []networkingv1.NetworkPolicyPeer
+		IPv6     bool
+		Expected []string
+	}{
+		{
+			Name:     "no peers matches anything",
+			Expected: []string{""},
+		},
+		{
+			Name: "ipBlock with except",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24", Except: []string{"192.168.0.0/25"}},
+			}},
+			Expected: []string{"192.168.0.128/25"},
+		},
+		{
+			Name: "ipBlock of the other family",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"},
+			}},
+			IPv6:     true,
+			Expected: []string{},
+		},
+		{
+			Name: "podSelector only matches the policy namespace",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}},
+			Expected: []string{"10.244.0.5"},
+		},
+		{
+			Name: "namespaceSelector only matches all pods in the namespaces",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "monitoring"}},
+			}},
+			Expected: []string{"10.244.1.7"},
+		},
+		{
+			Name: "empty namespaceSelector matches all namespaces",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}},
+			Expected: []string{"10.244.0.5", "10.244.1.8"},
+		},
+		{
+			Name: "namespaceSelector and podSelector",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "other"}},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}},
+			Expected: []string{"10.244.1.8"},
+		},
+		{
+			Name: "IPv6 pods",
+			Peers: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{},
+			}},
+			IPv6:     true,
+			Expected: []string{"fd00:10:244::5", "fd00:10:244::6"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := []string{}
+			for _, peer := range resolvePeers(tc.Peers, "default", tc.IPv6, pods, namespaceLabels) {
+				result = append(result, peer.cidr)
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicyRules(t *testing.T) {
+	t.Parallel()
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	}
+	pods := []*corev1.Pod{
+		testPod("default", "web", map[string]string{"app": "web"}, "10.244.0.5", "fd00:10:244::5"),
+		testPod("default", "client", map[string]string{"app": "client"}, "10.244.0.6", "fd00:10:244::6"),
+	}
+	http := intstr.FromString("http")
+	allowClient := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "allow-client"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{Port: &http}},
+			}},
+		},
+	}
+	cases := []struct {
+		Name            string
+		Policies        []*networkingv1.NetworkPolicy
+		IPv6            bool
+		ExpectedIngress [][]string
+		ExpectedEgress  [][]string
+	}{
+		{
+			Name: "no policies",
+		},
+		{
+			Name: "default deny ingress",
+			Policies: []*networkingv1.NetworkPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deny"},
+			}},
+			ExpectedIngress: [][]string{
+				{"-d", "10.244.0.6", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/client is isolated for ingress"},
+				{"-d", "10.244.0.5", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/web is isolated for ingress"},
+			},
+		},
+		{
+			Name: "default deny egress",
+			Policies: []*networkingv1.NetworkPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deny"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				},
+			}},
+			ExpectedEgress: [][]string{
+				{"-s", "10.244.0.6", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/client is isolated for egress"},
+				{"-s", "10.244.0.5", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/web is isolated for egress"},
+			},
+		},
+		{
+			Name:     "allow a pod on a named port",
+			Policies: []*networkingv1.NetworkPolicy{allowClient},
+			ExpectedIngress: [][]string{
+				{"-d", "10.244.0.5", "-s", "10.244.0.6", "-p", "tcp", "--dport", "8080", "-j", "RETURN"},
+				{"-d", "10.244.0.5", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/web is isolated for ingress"},
+			},
+		},
+		{
+			Name:     "IPv6",
+			Policies: []*networkingv1.NetworkPolicy{allowClient},
+			IPv6:     true,
+			ExpectedIngress: [][]string{
+				{"-d", "fd00:10:244::5", "-s", "fd00:10:244::6", "-p", "tcp", "--dport", "8080", "-j", "RETURN"},
+				{"-d", "fd00:10:244::5", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/web is isolated for ingress"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ingress, egress := policyRules("172.18.0.2", tc.IPv6, pods, namespaces, tc.Policies)
+			if !reflect.DeepEqual(ingress, tc.ExpectedIngress) {
+				t.Errorf("expected ingress %v but got %v", tc.ExpectedIngress, ingress)
+			}
+			if !reflect.DeepEqual(egress, tc.ExpectedEgress) {
+				t.Errorf("expected egress %v but got %v", tc.ExpectedEgress, egress)
+			}
+		})
+	}
+}
+
+func TestPolicyChainsRestoreInput(t *testing.T) {
+	t.Parallel()
+	ingress := [][]string{
+		{"-d", "10.244.0.5", "-j", "DROP", "-m", "comment", "--comment", "kindnetd: default/web is isolated for ingress"},
+	}
+	expected := `*filter
+:KIND-NP-INGRESS - [0:0]
+:KIND-NP-EGRESS - [0:0]
+-A KIND-NP-INGRESS -m conntrack --ctstate RELATED,ESTABLISHED -j RETURN
+-A KIND-NP-INGRESS -d 10.244.0.5 -j DROP -m comment --comment "kindnetd: default/web is isolated for ingress"
+-A KIND-NP-EGRESS -m conntrack --ctstate RELATED,ESTABLISHED -j RETURN
+COMMIT
+`
+	if result := string(policyChainsRestoreInput(ingress, nil)); result != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, result)
+	}
+}
@@ -49,3 +49,72 @@ func syncRoute(nodeIP, podCIDR string) error {
 
 	return nil
 }
+
+// deleteRoute removes the route to podCIDR via nodeIP if it exists
+func deleteRoute(nodeIP, podCIDR string) error {
+	// parse subnet
+	dst, err := netlink.ParseIPNet(podCIDR)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(nodeIP)
+	routeToDst := netlink.Route{Dst: dst, Gw: ip}
+	routes, err := netlink.RouteListFiltered(nl.GetIPFamily(ip), &routeToDst, netlink.RT_FILTER_DST|netlink.RT_FILTER_GW)
+	if err != nil {
+		return err
+	}
+	for i := range routes {
+		if err := netlink.RouteDel(&routes[i]); err != nil {
+			return err
+		}
+		klog.Infof("Deleted route %v \n", routes[i])
+	}
+	return nil
+}
+
+// deleteRoutesExcept removes all gateway routes to destinations within
+// podSubnets, other than the wanted routes
+func deleteRoutesExcept(podSubnets []string, wanted []nodeRoute) error {
+	subnets := []*net.IPNet{}
+	for _, podSubnet := range podSubnets {
+		subnet, err := netlink.ParseIPNet(podSubnet)
+		if err != nil {
+			return err
+		}
+		subnets = append(subnets, subnet)
+	}
+
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return err
+	}
+	for i := range routes {
+		route := &routes[i]
+		// only consider routes via another node to part of the pod subnets
+		if route.Dst == nil || route.Gw == nil || !subnetsContain(subnets, route.Dst) {
+			continue
+		}
+		current := nodeRoute{podCIDR: route.Dst.String(), nodeIP: route.Gw.String()}
+		if containsRoute(wanted, current) {
+			continue
+		}
+		if err := netlink.RouteDel(route); err != nil {
+			return err
+		}
+		klog.Infof("Deleted stale route %v \n", *route)
+	}
+	return nil
+}
+
+// subnetsContain returns true if dst is within any of subnets
+func subnetsContain(subnets []*net.IPNet, dst *net.IPNet) bool {
+	dstOnes, _ := dst.Mask.Size()
+	for _, subnet := range subnets {
+		ones, _ := subnet.Mask.Size()
+		if subnet.Contains(dst.IP) && dstOnes >= ones {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"k8s.io/klog"
+)
+
+/* load balancer address management */
+
+// linkForIP returns the interface with the address ip, and the address
+// with the interface's network mask
+func linkForIP(ip string) (netlink.Link, *net.IPNet, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, link := range links {
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, addr := range addrs {
+			if addr.IP.String() == ip {
+				return link, addr.IPNet, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("no interface has the address %s", ip)
+}
+
+// defaultPool returns the second to last block of 32 addresses in the
+// network of hostNet, docker assigns addresses from the start of the network
+// and the last block contains the broadcast address
+func defaultPool(hostNet *net.IPNet) (*net.IPNet, error) {
+	ones, bits := hostNet.Mask.Size()
+	// the network must have at least four blocks of 32 addresses, so the
+	// pool is never in the first block
+	if bits-ones < 7 {
+		return nil, fmt.Errorf("the node network %s is too small for a default address pool", hostNet)
+	}
+	// start with the last address in the network
+	ip := hostNet.IP.Mask(hostNet.Mask)
+	for i := range ip {
+		ip[i] |= ^hostNet.Mask[i]
+	}
+	// the second to last block of 32 starts 63 addresses before the last
+	// address, where the low 6 bits are all clear
+	ip[len(ip)-1] &^= 0x3f
+	mask := net.CIDRMask(bits-5, bits)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
+}
+
+// gatewayIPs returns the gateways of the routes through link
+func gatewayIPs(link netlink.Link) ([]string, error) {
+	routes, err := netlink.RouteList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, err
+	}
+	gateways := []string{}
+	for _, route := range routes {
+		if route.Gw != nil {
+			gateways = append(gateways, route.Gw.String())
+		}
+	}
+	return gateways, nil
+}
+
+// ownedAddress returns true if addr may have been added for a LoadBalancer,
+// it is a single address in pool that is not reserved
+func ownedAddress(addr *net.IPNet, pool *net.IPNet, reserved []string) bool {
+	if ones, bits := addr.Mask.Size(); ones != bits {
+		return false
+	}
+	return pool.Contains(addr.IP) && !containsString(reserved, addr.IP.String())
+}
+
+// ensureAddresses makes the addresses within pool on link exactly want,
+// the reserved addresses are never removed
+func ensureAddresses(link netlink.Link, pool *net.IPNet, reserved, want []string) error {
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return err
+	}
+	present := map[string]bool{}
+	for i := range addrs {
+		addr := &addrs[i]
+		if !ownedAddress(addr.IPNet, pool, reserved) {
+			continue
+		}
+		if containsString(want, addr.IP.String()) {
+			present[addr.IP.String()] = true
+			continue
+		}
+		if err := netlink.AddrDel(link, addr); err != nil {
+			return err
+		}
+		klog.Infof("Removed address %s\n", addr.IP)
+	}
+	for _, ip := range want {
+		if present[ip] {
+			continue
+		}
+		parsed := net.ParseIP(ip)
+		bits := 8 * net.IPv6len
+		if parsed.To4() != nil {
+			parsed, bits = parsed.To4(), 8*net.IPv4len
+		}
+		addr := &netlink.Addr{IPNet: &net.IPNet{IP: parsed, Mask: net.CIDRMask(bits, bits)}}
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return err
+		}
+		klog.Infof("Added address %s\n", ip)
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
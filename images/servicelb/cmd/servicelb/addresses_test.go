@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestDefaultPool(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		HostNet     string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "docker default network",
+			HostNet:  "172.18.0.2/16",
+			Expected: "172.18.255.192/27",
+		},
+		{
+			Name:     "smallest network",
+			HostNet:  "192.168.1.10/25",
+			Expected: "192.168.1.64/27",
+		},
+		{
+			Name:        "too small",
+			HostNet:     "192.168.1.10/26",
+			ExpectError: true,
+		},
+		{
+			Name:     "IPv6",
+			HostNet:  "fc00:f853:ccd:e793::2/64",
+			Expected: "fc00:f853:ccd:e793:ffff:ffff:ffff:ffc0/123",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ip, hostNet, err := net.ParseCIDR(tc.HostNet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the node's address with the network mask, as on the interface
+			hostNet.IP = ip
+			if ip4 := ip.To4(); ip4 != nil {
+				hostNet.IP = ip4
+			}
+			pool, err := defaultPool(hostNet)
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectError {
+				return
+			}
+			if pool.String() != tc.Expected {
+				t.Errorf("expected pool %s but got %s", tc.Expected, pool)
+			}
+			if pool.Contains(ip) {
+				t.Errorf("pool %s contains the node address %s", pool, ip)
+			}
+		})
+	}
+}
+
+func TestOwnedAddress(t *testing.T) {
+	t.Parallel()
+	_, pool, _ := net.ParseCIDR("172.18.255.192/27")
+	reserved := []string{"172.18.255.193"}
+	cases := []struct {
+		Name     string
+		Addr     string
+		Expected bool
+	}{
+		{
+			Name:     "single address in the pool",
+			Addr:     "172.18.255.200/32",
+			Expected: true,
+		},
+		{
+			Name:     "single address outside the pool",
+			Addr:     "172.18.0.200/32",
+			Expected: false,
+		},
+		{
+			Name:     "address with a network mask",
+			Addr:     "172.18.255.200/16",
+			Expected: false,
+		},
+		{
+			Name:     "reserved address",
+			Addr:     "172.18.255.193/32",
+			Expected: false,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ip, addr, err := net.ParseCIDR(tc.Addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr.IP = ip
+			if result := ownedAddress(addr, pool, reserved); result != tc.Expected {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// ServiceController assigns IPs from the address pool to Services of type
+// LoadBalancer and holds the assigned IPs on the node's interface
+type ServiceController struct {
+	client         kubernetes.Interface
+	serviceLister  corelisters.ServiceLister
+	servicesSynced cache.InformerSynced
+	nodeLister     corelisters.NodeLister
+	nodesSynced    cache.InformerSynced
+	queue          workqueue.RateLimitingInterface
+
+	link netlink.Link
+	pool *net.IPNet
+	// reserved are the IPs in use by this node's network, never assigned
+	// the IPs of all nodes are never assigned either, see isReserved
+	reserved []string
+
+	// allocated maps assigned IPs to the key of the service they belong to
+	// NOTE: only accessed from the single worker goroutine
+	allocated map[string]string
+}
+
+// NewServiceController returns a new ServiceController for the service
+// informer, assigning IPs other than reserved and the nodes' IPs from pool and
+// adding them to link
+func NewServiceController(client kubernetes.Interface, serviceInformer coreinformers.ServiceInformer, nodeInformer coreinformers.NodeInformer, link netlink.Link, pool *net.IPNet, reserved []string) *ServiceController {
+	c := &ServiceController{
+		client:         client,
+		serviceLister:  serviceInformer.Lister(),
+		servicesSynced: serviceInformer.Informer().HasSynced,
+		nodeLister:     nodeInformer.Lister(),
+		nodesSynced:    nodeInformer.Informer().HasSynced,
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "services"),
+		link:           link,
+		pool:           pool,
+		reserved:       reserved,
+		allocated:      map[string]string{},
+	}
+	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			c.enqueue(new)
+		},
+		DeleteFunc: c.enqueue,
+	})
+	// services may hold the IP of a node that joined later, which must
+	// then be assigned a new IP
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAll()
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if !reflect.DeepEqual(old.(*corev1.Node).Status.Addresses, new.(*corev1.Node).Status.Addresses) {
+				c.enqueueAll()
+			}
+		},
+	})
+	return c
+}
+
+func (c *ServiceController) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueAll enqueues all LoadBalancer services
+func (c *ServiceController) enqueueAll() {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, service := range services {
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			c.enqueue(service)
+		}
+	}
+}
+
+// Run waits for the service cache to sync, records the IPs already assigned
+// and then processes service events until stopCh is closed
+func (c *ServiceController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Waiting for service informer caches to sync")
+	if !cache.WaitForCacheSync(stopCh, c.servicesSynced, c.nodesSynced) {
+		return fmt.Errorf("failed to wait for service caches to sync")
+	}
+
+	// keep the IPs assigned by a previous leader, services sharing an IP
+	// are assigned a new one when they are synced
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].CreationTimestamp.Before(&services[j].CreationTimestamp)
+	})
+	for _, service := range services {
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, ip := range c.ingressIPs(service) {
+			if _, used := c.allocated[ip]; !used {
+				c.allocated[ip] = service.Namespace + "/" + service.Name
+			}
+		}
+	}
+
+	// a single worker, the allocations are not safe for concurrent use
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (c *ServiceController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *ServiceController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncService(key.(string)); err != nil {
+		// retry with backoff
+		klog.Errorf("Failed to sync service %s, retrying: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// syncService assigns an IP to the service with key if it is of type
+// LoadBalancer, or releases its IP otherwise
+func (c *ServiceController) syncService(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		c.release(key)
+		return c.syncAddresses()
+	}
+	if err != nil {
+		return err
+	}
+
+	ingress := []corev1.LoadBalancerIngress{}
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		ip, err := c.allocate(key, service.Spec.LoadBalancerIP, c.ingressIPs(service))
+		if err != nil {
+			return err
+		}
+		ingress = append(ingress, corev1.LoadBalancerIngress{IP: ip})
+	} else {
+		c.release(key)
+		// only clear the status if it was set by us
+		if len(c.ingressIPs(service)) == 0 {
+			ingress = service.Status.LoadBalancer.Ingress
+		}
+	}
+
+	if !ingressEqual(service.Status.LoadBalancer.Ingress, ingress) {
+		updated := service.DeepCopy()
+		updated.Status.LoadBalancer.Ingress = ingress
+		if _, err := c.client.CoreV1().Services(namespace).UpdateStatus(updated); err != nil {
+			return err
+		}
+		klog.Infof("Updated LoadBalancer ingress of service %s to %v\n", key, ingress)
+	}
+	return c.syncAddresses()
+}
+
+// allocate returns the IP assigned to the service with key, requested if
+// set, otherwise one of current or a free IP from the pool
+func (c *ServiceController) allocate(key, requested string, current []string) (string, error) {
+	ip := ""
+	if requested != "" {
+		parsed := net.ParseIP(requested)
+		if parsed == nil || !c.pool.Contains(parsed) {
+			return "", fmt.Errorf("requested loadBalancerIP %s is not in the address pool %s", requested, c.pool)
+		}
+		ip = parsed.String()
+		if c.isReserved(ip) {
+			return "", fmt.Errorf("requested loadBalancerIP %s is in use by the node network", requested)
+		}
+		if owner, used := c.allocated[ip]; used && owner != key {
+			return "", fmt.Errorf("requested loadBalancerIP %s is already assigned to %s", requested, owner)
+		}
+	}
+	for _, candidate := range current {
+		if ip != "" {
+			break
+		}
+		if c.isReserved(candidate) {
+			continue
+		}
+		if owner, used := c.allocated[candidate]; !used || owner == key {
+			ip = candidate
+		}
+	}
+	for candidate := c.pool.IP.Mask(c.pool.Mask); ip == "" && c.pool.Contains(candidate); candidate = nextIP(candidate) {
+		if _, used := c.allocated[candidate.String()]; !used && !c.isReserved(candidate.String()) {
+			ip = candidate.String()
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("the address pool %s is exhausted", c.pool)
+	}
+
+	// each service holds exactly one IP
+	c.release(key)
+	c.allocated[ip] = key
+	return ip, nil
+}
+
+// isReserved returns true if ip is in use by the node network, i.e. it is
+// reserved or the InternalIP of any node
+func (c *ServiceController) isReserved(ip string) bool {
+	if containsString(c.reserved, ip) {
+		return true
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		// the lister only lists the cache, this does not happen
+		utilruntime.HandleError(err)
+		return false
+	}
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP && net.ParseIP(address.Address).String() == ip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// release frees the IPs assigned to the service with key
+func (c *ServiceController) release(key string) {
+	for ip, owner := range c.allocated {
+		if owner == key {
+			delete(c.allocated, ip)
+		}
+	}
+}
+
+// syncAddresses makes the addresses on the interface match the allocations
+func (c *ServiceController) syncAddresses() error {
+	want := make([]string, 0, len(c.allocated))
+	for ip := range c.allocated {
+		want = append(want, ip)
+	}
+	return ensureAddresses(c.link, c.pool, c.reserved, want)
+}
+
+// ingressIPs returns the service's ingress IPs that are in the pool
+func (c *ServiceController) ingressIPs(service *corev1.Service) []string {
+	ips := []string{}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ip := net.ParseIP(ingress.IP); ip != nil && c.pool.Contains(ip) {
+			ips = append(ips, ip.String())
+		}
+	}
+	return ips
+}
+
+func ingressEqual(a, b []corev1.LoadBalancerIngress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nextIP returns the IP after ip
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
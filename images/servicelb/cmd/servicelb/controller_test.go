@@ -0,0 +1,188 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestAllocate(t *testing.T) {
+	t.Parallel()
+	_, pool, _ := net.ParseCIDR("172.18.255.192/30")
+	cases := []struct {
+		Name        string
+		Allocated   map[string]string
+		Reserved    []string
+		NodeIPs     []string
+		Requested   string
+		Current     []string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "first free address",
+			Expected: "172.18.255.192",
+		},
+		{
+			Name:      "skips allocated and reserved addresses",
+			Allocated: map[string]string{"172.18.255.192": "default/other"},
+			Reserved:  []string{"172.18.255.193"},
+			Expected:  "172.18.255.194",
+		},
+		{
+			Name:     "skips other nodes' addresses",
+			NodeIPs:  []string{"172.18.255.192", "172.18.255.193"},
+			Expected: "172.18.255.194",
+		},
+		{
+			Name:     "current address now used by a node",
+			NodeIPs:  []string{"172.18.255.195"},
+			Current:  []string{"172.18.255.195"},
+			Expected: "172.18.255.192",
+		},
+		{
+			Name:     "keeps the current address",
+			Current:  []string{"172.18.255.195"},
+			Expected: "172.18.255.195",
+		},
+		{
+			Name:      "current address owned by another service",
+			Allocated: map[string]string{"172.18.255.195": "default/other"},
+			Current:   []string{"172.18.255.195"},
+			Expected:  "172.18.255.192",
+		},
+		{
+			Name:      "requested address",
+			Requested: "172.18.255.194",
+			Current:   []string{"172.18.255.195"},
+			Expected:  "172.18.255.194",
+		},
+		{
+			Name:        "requested address outside the pool",
+			Requested:   "172.18.0.10",
+			ExpectError: true,
+		},
+		{
+			Name:        "requested address in use",
+			Allocated:   map[string]string{"172.18.255.194": "default/other"},
+			Requested:   "172.18.255.194",
+			ExpectError: true,
+		},
+		{
+			Name:        "requested address reserved",
+			Reserved:    []string{"172.18.255.194"},
+			Requested:   "172.18.255.194",
+			ExpectError: true,
+		},
+		{
+			Name:        "requested address of another node",
+			NodeIPs:     []string{"172.18.255.194"},
+			Requested:   "172.18.255.194",
+			ExpectError: true,
+		},
+		{
+			Name: "exhausted",
+			Allocated: map[string]string{
+				"172.18.255.192": "default/a",
+				"172.18.255.193": "default/b",
+				"172.18.255.194": "default/c",
+			},
+			Reserved:    []string{"172.18.255.195"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			allocated := map[string]string{}
+			for ip, owner := range tc.Allocated {
+				allocated[ip] = owner
+			}
+			nodes := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for i, ip := range tc.NodeIPs {
+				name := fmt.Sprintf("kind-worker%d", i)
+				node := &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Status: corev1.NodeStatus{
+						Addresses: []corev1.NodeAddress{
+							{Type: corev1.NodeHostName, Address: name},
+							{Type: corev1.NodeInternalIP, Address: ip},
+						},
+					},
+				}
+				if err := nodes.Add(node); err != nil {
+					t.Fatalf("unexpected error adding node: %v", err)
+				}
+			}
+			c := &ServiceController{
+				pool:       pool,
+				reserved:   tc.Reserved,
+				nodeLister: corelisters.NewNodeLister(nodes),
+				allocated:  allocated,
+			}
+			ip, err := c.allocate("default/lb", tc.Requested, tc.Current)
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectError {
+				return
+			}
+			if ip != tc.Expected {
+				t.Errorf("expected %s but got %s", tc.Expected, ip)
+			}
+			for other, owner := range c.allocated {
+				if owner == "default/lb" && other != ip {
+					t.Errorf("service still holds %s after allocating %s", other, ip)
+				}
+			}
+		})
+	}
+}
+
+func TestNextIP(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		IP       string
+		Expected string
+	}{
+		{IP: "10.0.0.1", Expected: "10.0.0.2"},
+		{IP: "10.0.0.255", Expected: "10.0.1.0"},
+		{IP: "10.255.255.255", Expected: "11.0.0.0"},
+		{IP: "fd00::ffff", Expected: "fd00::1:0"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.IP, func(t *testing.T) {
+			t.Parallel()
+			ip := net.ParseIP(tc.IP)
+			if result := nextIP(ip).String(); result != tc.Expected {
+				t.Errorf("expected %s but got %s", tc.Expected, result)
+			}
+			if ip.String() != tc.IP {
+				t.Errorf("nextIP modified its argument to %s", ip)
+			}
+		})
+	}
+}
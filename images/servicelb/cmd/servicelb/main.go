@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+)
+
+// servicelb is a simple controller implementing Services of type LoadBalancer
+// for kind clusters
+// servicelb assigns each LoadBalancer Service an IP from an address pool in
+// the network the nodes share, and adds the IPs to the elected leader's
+// interface so the node answers ARP / NDP for them. kube-proxy then handles
+// traffic to these IPs like any other LoadBalancer ingress IP.
+//
+// input envs:
+// - HOST_IP: should be populated by downward API
+// - POD_NAME: should be populated by downward API, used for leader election
+// - ADDRESS_POOL: the CIDR to assign IPs from, defaults to a range at the
+//   end of the node network
+
+// resyncPeriod is how often all services are reconciled, even without changes
+const resyncPeriod = 30 * time.Second
+
+// name and namespace of the lease used for leader election
+const (
+	leaseName      = "kind-servicelb"
+	leaseNamespace = "kube-system"
+)
+
+func main() {
+	// enable logging
+	klog.InitFlags(nil)
+	_ = flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	// create a Kubernetes client
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		panic(err.Error())
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// find the node's interface on the network shared by the nodes
+	hostIP, podName := os.Getenv("HOST_IP"), os.Getenv("POD_NAME")
+	link, hostNet, err := linkForIP(hostIP)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// pick the address pool
+	var pool *net.IPNet
+	if rawPool := os.Getenv("ADDRESS_POOL"); rawPool != "" {
+		_, pool, err = net.ParseCIDR(rawPool)
+	} else {
+		pool, err = defaultPool(hostNet)
+	}
+	if err != nil {
+		panic(err.Error())
+	}
+	klog.Infof("Assigning LoadBalancer IPs from %s on %s\n", pool, link.Attrs().Name)
+
+	// the node's own IP and gateways are never assigned or removed, the other
+	// nodes' IPs are never assigned either
+	gateways, err := gatewayIPs(link)
+	if err != nil {
+		panic(err.Error())
+	}
+	reserved := append([]string{hostNet.IP.String()}, gateways...)
+
+	// only the leader holds the addresses, remove any left by a previous run
+	if err := ensureAddresses(link, pool, reserved, nil); err != nil {
+		panic(err.Error())
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: leaseNamespace,
+		},
+		Client: clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: podName,
+		},
+	}
+	leaderelection.RunOrDie(context.Background(), leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("%s is the leader, handling LoadBalancer services\n", podName)
+				informerFactory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
+				controller := NewServiceController(clientset, informerFactory.Core().V1().Services(), informerFactory.Core().V1().Nodes(), link, pool, reserved)
+				informerFactory.Start(ctx.Done())
+				if err := controller.Run(ctx.Done()); err != nil {
+					klog.Fatalf("service controller failed: %v", err)
+				}
+			},
+			OnStoppedLeading: func() {
+				// another instance will take over the addresses, so drop them
+				// and restart to start over as a follower
+				if err := ensureAddresses(link, pool, reserved, nil); err != nil {
+					klog.Errorf("Failed to remove LoadBalancer addresses: %v", err)
+				}
+				klog.Fatalf("%s lost the leader election", podName)
+			},
+		},
+	})
+}
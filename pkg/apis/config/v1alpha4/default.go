@@ -53,6 +53,9 @@ func SetDefaultsCluster(obj *Cluster) {
 		if obj.Networking.IPFamily == "ipv6" {
 			obj.Networking.PodSubnet = "fd00:10:244::/64"
 		}
+		if obj.Networking.IPFamily == "dual" {
+			obj.Networking.PodSubnet = "10.244.0.0/16,fd00:10:244::/56"
+		}
 	}
 	// default the service CIDR using the kubeadm default
 	// https://github.com/kubernetes/kubernetes/blob/746404f82a28e55e0b76ffa7e40306fb88eb3317/cmd/kubeadm/app/apis/kubeadm/v1beta2/defaults.go#L32
@@ -62,6 +65,31 @@ func SetDefaultsCluster(obj *Cluster) {
 		if obj.Networking.IPFamily == "ipv6" {
 			obj.Networking.ServiceSubnet = "fd00:10:96::/112"
 		}
+		if obj.Networking.IPFamily == "dual" {
+			obj.Networking.ServiceSubnet = "10.96.0.0/12,fd00:10:96::/112"
+		}
+	}
+	// default to iptables proxy mode
+	if obj.Networking.KubeProxyMode == "" {
+		obj.Networking.KubeProxyMode = IPTablesProxyMode
+	}
+	// default to the haproxy load balancer
+	if obj.LoadBalancer.Type == "" {
+		obj.LoadBalancer.Type = HAProxyLoadBalancer
+	}
+	// default the directory backing local-path volumes
+	if obj.Storage.LocalPath == "" {
+		obj.Storage.LocalPath = DefaultStorageLocalPath
+	}
+	// default the load balancer timeouts to the previous fixed values
+	if obj.LoadBalancer.ConnectTimeout == "" {
+		obj.LoadBalancer.ConnectTimeout = "5s"
+	}
+	if obj.LoadBalancer.ClientTimeout == "" {
+		obj.LoadBalancer.ClientTimeout = "50s"
+	}
+	if obj.LoadBalancer.ServerTimeout == "" {
+		obj.LoadBalancer.ServerTimeout = "50s"
 	}
 }
 
@@ -74,4 +102,13 @@ func SetDefaultsNode(obj *Node) {
 	if obj.Role == "" {
 		obj.Role = ControlPlaneRole
 	}
+
+	if obj.Ingress != nil {
+		if obj.Ingress.HTTPPort == 0 {
+			obj.Ingress.HTTPPort = 80
+		}
+		if obj.Ingress.HTTPSPort == 0 {
+			obj.Ingress.HTTPSPort = 443
+		}
+	}
 }
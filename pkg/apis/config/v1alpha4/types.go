@@ -31,6 +31,23 @@ type Cluster struct {
 	// Networking contains cluster wide network settings
 	Networking Networking `yaml:"networking,omitempty"`
 
+	// LoadBalancer contains settings for the external load balancer in front
+	// of the API servers of clusters with multiple control-plane nodes
+	LoadBalancer LoadBalancer `yaml:"loadBalancer,omitempty"`
+
+	// Storage contains settings for the default storage provisioner and
+	// StorageClass
+	Storage Storage `yaml:"storage,omitempty"`
+
+	// FeatureGates contains a map of Kubernetes feature gates to whether they
+	// are enabled. The feature gates are set on every Kubernetes component:
+	// the API server, controller manager, scheduler, kubelet and kube-proxy.
+	FeatureGates map[string]bool `yaml:"featureGates,omitempty"`
+
+	// RuntimeConfig is the API server's --runtime-config, a map of API
+	// groups / versions to enable or disable, eg "api/alpha": "true"
+	RuntimeConfig map[string]string `yaml:"runtimeConfig,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -55,8 +72,23 @@ type Cluster struct {
 	// https://tools.ietf.org/html/rfc6902
 	//
 	// The cluster-level patches are appied before the node-level patches.
+	//
+	// If a patch sets `nodes` it will only be applied to the selected nodes.
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902 `yaml:"kubeadmConfigPatchesJSON6902,omitempty"`
 
+	// KubeadmConfigSelectedPatches are merge patches like KubeadmConfigPatches,
+	// that are only applied to the generated kubeadm config of the nodes
+	// matching their selector.
+	//
+	// Each node's kubeadm config is patched in this order:
+	// - cluster-level merge patches: KubeadmConfigPatches, then the matching
+	//   KubeadmConfigSelectedPatches
+	// - the matching cluster-level KubeadmConfigPatchesJSON6902
+	// - the node's own KubeadmConfigPatches
+	// - the node's own KubeadmConfigPatchesJSON6902
+	// Within each of these the patches are applied in the order listed.
+	KubeadmConfigSelectedPatches []SelectedPatch `yaml:"kubeadmConfigSelectedPatches,omitempty"`
+
 	// ContainerdConfigPatches are applied to every node's containerd config
 	// in the order listed.
 	// These should be toml stringsto be applied as merge patches
@@ -66,6 +98,20 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty"`
+
+	// Manifests are applied to the cluster in the order listed with
+	// `kubectl apply`, after the default CNI and StorageClass are installed
+	// and all nodes have joined.
+	// These may be used to install CRDs, namespaces, RBAC etc.
+	Manifests []Manifest `yaml:"manifests,omitempty"`
+
+	// Hooks are commands run on the host or in the nodes at defined phases
+	// of cluster creation
+	Hooks Hooks `yaml:"hooks,omitempty"`
+
+	// Files are written to every node before Kubernetes is set up.
+	// Node-level files are written after the cluster-level files.
+	Files []File `yaml:"files,omitempty"`
 }
 
 // TypeMeta partially copies apimachinery/pkg/apis/meta/v1.TypeMeta
@@ -100,6 +146,11 @@ type Node struct {
 	// binded to a host Port
 	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty"`
 
+	// Ingress marks the node as ready for an ingress controller, forwarding
+	// host ports to the node's ports 80 and 443 and labeling the node with
+	// ingress-ready=true
+	Ingress *Ingress `yaml:"ingress,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -127,6 +178,22 @@ type Node struct {
 	// The node-level patches will be applied after the cluster-level patches
 	// have been applied. (See Cluster.KubeadmConfigPatchesJSON6902)
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902 `yaml:"kubeadmConfigPatchesJSON6902,omitempty"`
+
+	// Files are written to the node before Kubernetes is set up, after the
+	// cluster-level files have been written. (See Cluster.Files)
+	Files []File `yaml:"files,omitempty"`
+
+	// ContainerdConfigPatches are applied to the node's containerd config
+	// in the order listed, after the cluster-level patches have been applied.
+	// (See Cluster.ContainerdConfigPatches)
+	// These should be toml strings to be applied as merge patches
+	ContainerdConfigPatches []string `yaml:"containerdConfigPatches,omitempty"`
+
+	// ContainerdConfigPatchesJSON6902 are applied to the node's containerd
+	// config in the order listed, after the cluster-level patches have been
+	// applied. (See Cluster.ContainerdConfigPatchesJSON6902)
+	// These should be YAML or JSON formatting RFC 6902 JSON patches
+	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty"`
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -144,7 +211,8 @@ const (
 
 // Networking contains cluster wide network settings
 type Networking struct {
-	// IPFamily is the network cluster model, currently it can be ipv4 or ipv6
+	// IPFamily is the network cluster model, currently it can be ipv4, ipv6
+	// or dual (dual-stack IPv4 and IPv6)
 	IPFamily ClusterIPFamily `yaml:"ipFamily,omitempty"`
 	// APIServerPort is the listen port on the host for the Kubernetes API Server
 	// Defaults to a random port on the host
@@ -156,13 +224,96 @@ type Networking struct {
 	APIServerAddress string `yaml:"apiServerAddress,omitempty"`
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
+	// For dual stack clusters this is an IPv4 and an IPv6 CIDR, comma separated
 	PodSubnet string `yaml:"podSubnet,omitempty"`
 	// ServiceSubnet is the CIDR used for services VIPs
 	// kind will select a default if unspecified for IPv6
+	// For dual stack clusters this is an IPv4 and an IPv6 CIDR, comma separated
 	ServiceSubnet string `yaml:"serviceSubnet,omitempty"`
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool `yaml:"disableDefaultCNI,omitempty"`
+	// If EnableNetworkPolicy is true, the default CNI will enforce
+	// NetworkPolicies. This requires the default CNI.
+	EnableNetworkPolicy bool `yaml:"enableNetworkPolicy,omitempty"`
+	// If EnableLoadBalancer is true, kind will deploy a controller assigning
+	// IPs from the network the nodes share to Services of type LoadBalancer
+	EnableLoadBalancer bool `yaml:"enableLoadBalancer,omitempty"`
+	// LoadBalancerAddressPool is the CIDR LoadBalancer IPs are assigned from,
+	// it should be an unused range of the network the nodes share
+	// Defaults to a range at the end of the node network
+	LoadBalancerAddressPool string `yaml:"loadBalancerAddressPool,omitempty"`
+	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs
+	// mode, or if kube-proxy should not be deployed at all (none)
+	// Defaults to iptables
+	KubeProxyMode ProxyMode `yaml:"kubeProxyMode,omitempty"`
+}
+
+// LoadBalancer contains settings for the external control-plane load balancer
+type LoadBalancer struct {
+	// Type is the load balancer implementation, one of haproxy, envoy or nginx
+	// Defaults to haproxy
+	Type LoadBalancerType `yaml:"type,omitempty"`
+	// Image is the load balancer image to run
+	// Defaults to an image for the Type
+	Image string `yaml:"image,omitempty"`
+	// ConfigTemplate replaces the default config template of the Type.
+	// It is a Go text/template executed with the API server backends, ports
+	// and timeouts, see the configuration docs for details.
+	ConfigTemplate string `yaml:"configTemplate,omitempty"`
+	// ConnectTimeout is the maximum time to wait for a connection to an API
+	// server to be established, as a duration string eg "5s"
+	// Defaults to 5s
+	ConnectTimeout string `yaml:"connectTimeout,omitempty"`
+	// ClientTimeout is the maximum time a client connection may be idle
+	// Defaults to 50s
+	ClientTimeout string `yaml:"clientTimeout,omitempty"`
+	// ServerTimeout is the maximum time an API server connection may be idle
+	// Defaults to 50s
+	ServerTimeout string `yaml:"serverTimeout,omitempty"`
+	// StatsPort is the host port the load balancer statistics page is
+	// published on, at /stats on the APIServerAddress
+	// If unset the statistics page is not published on the host
+	StatsPort int32 `yaml:"statsPort,omitempty"`
+}
+
+// LoadBalancerType is the load balancer implementation
+type LoadBalancerType string
+
+const (
+	// HAProxyLoadBalancer sets LoadBalancerType to haproxy
+	HAProxyLoadBalancer LoadBalancerType = "haproxy"
+	// EnvoyLoadBalancer sets LoadBalancerType to envoy
+	EnvoyLoadBalancer LoadBalancerType = "envoy"
+	// NginxLoadBalancer sets LoadBalancerType to nginx
+	NginxLoadBalancer LoadBalancerType = "nginx"
+)
+
+// DefaultStorageLocalPath is the default Storage LocalPath
+const DefaultStorageLocalPath = "/var/local-path-provisioner"
+
+// Storage contains settings for the default storage provisioner and
+// StorageClass installed by kind
+type Storage struct {
+	// DisableDefaultStorageClass disables installing the default storage
+	// provisioner and StorageClass, eg to make a CSI driver the only default
+	DisableDefaultStorageClass bool `yaml:"disableDefaultStorageClass,omitempty"`
+	// NonDefaultStorageClass installs the default storage provisioner's
+	// StorageClass without marking it as the cluster's default StorageClass
+	NonDefaultStorageClass bool `yaml:"nonDefaultStorageClass,omitempty"`
+	// Manifest replaces the default storage provisioner and StorageClass
+	// with a user manifest
+	Manifest *Manifest `yaml:"manifest,omitempty"`
+	// LocalPath is the directory on the nodes that the default storage
+	// provisioner creates volumes in
+	// Defaults to /var/local-path-provisioner
+	LocalPath string `yaml:"localPath,omitempty"`
+	// PersistentHostPath is a directory on the host that backs the default
+	// storage provisioner's volumes, so that they survive deleting the
+	// cluster. A directory for the cluster under it is mounted at LocalPath
+	// in every node, and volumes are created in <namespace>/<pvc name>
+	// there, so a recreated cluster binds the same PVCs to their data.
+	PersistentHostPath string `yaml:"persistentHostPath,omitempty"`
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -173,8 +324,108 @@ const (
 	IPv4Family ClusterIPFamily = "ipv4"
 	// IPv6Family sets ClusterIPFamily to ipv6
 	IPv6Family ClusterIPFamily = "ipv6"
+	// DualStackFamily sets ClusterIPFamily to dual
+	DualStackFamily ClusterIPFamily = "dual"
 )
 
+// ProxyMode defines a proxy mode for kube-proxy
+type ProxyMode string
+
+const (
+	// IPTablesProxyMode sets ProxyMode to iptables
+	IPTablesProxyMode ProxyMode = "iptables"
+	// IPVSProxyMode sets ProxyMode to ipvs
+	IPVSProxyMode ProxyMode = "ipvs"
+	// NoneProxyMode disables kube-proxy, eg for a CNI that replaces it
+	NoneProxyMode ProxyMode = "none"
+)
+
+// Manifest is a Kubernetes manifest to apply to the cluster after creation
+// Exactly one of Path and Content should be set.
+// In yaml this looks like:
+//  path: ./crds/
+//  waitForRollout: true
+// or:
+//  content: |
+//    apiVersion: v1
+//    kind: Namespace
+//    metadata:
+//      name: test
+type Manifest struct {
+	// Path is a path on the host to a manifest file, or a directory
+	// containing manifest files (.yaml, .yml, .json), which are applied in
+	// lexical order. Relative paths are relative to the current working
+	// directory.
+	Path string `yaml:"path,omitempty"`
+	// Content is an inline manifest
+	Content string `yaml:"content,omitempty"`
+	// If WaitForRollout is true, kind will wait for any Deployments,
+	// DaemonSets and StatefulSets in the manifest to finish rolling out
+	WaitForRollout bool `yaml:"waitForRollout,omitempty"`
+}
+
+// Hooks contains the commands to run at each phase of cluster creation
+// The hooks for each phase are run in the order listed.
+type Hooks struct {
+	// PostProvision hooks run after the node containers are created,
+	// before anything else is configured
+	PostProvision []Hook `yaml:"postProvision,omitempty"`
+	// PreKubeadmInit hooks run after the kubeadm config is written to the
+	// nodes, before kubeadm init
+	PreKubeadmInit []Hook `yaml:"preKubeadmInit,omitempty"`
+	// PostJoin hooks run after all nodes have joined the cluster
+	PostJoin []Hook `yaml:"postJoin,omitempty"`
+	// PostCreate hooks run once cluster creation is complete, after the
+	// kubeconfig is exported
+	PostCreate []Hook `yaml:"postCreate,omitempty"`
+}
+
+// Hook is a command to run at some phase of cluster creation
+// In yaml this looks like:
+//  name: update-ca-certificates
+//  command: ["update-ca-certificates"]
+//  roles: ["worker"]
+// or:
+//  command: ["./hack/post-create.sh"]
+//  runOnHost: true
+type Hook struct {
+	// Name is used when reporting progress and errors
+	// Defaults to the command
+	Name string `yaml:"name,omitempty"`
+	// Command is the command and arguments to run, no shell is used
+	Command []string `yaml:"command,omitempty"`
+	// If RunOnHost is true, the command runs on the host, with the
+	// KIND_CLUSTER_NAME environment variable set to the cluster name.
+	// Otherwise it runs in each matching node.
+	RunOnHost bool `yaml:"runOnHost,omitempty"`
+	// Roles selects the nodes to run the command in
+	// Defaults to all nodes
+	Roles []NodeRole `yaml:"roles,omitempty"`
+}
+
+// File is a file to write into nodes before Kubernetes is set up
+// Exactly one of Content and HostPath should be set.
+// In yaml this looks like:
+//  path: /etc/kubernetes/audit-policy.yaml
+//  hostPath: ./audit-policy.yaml
+//  permissions: "0600"
+//  owner: root:root
+type File struct {
+	// Path is the absolute path of the file within the node
+	Path string `yaml:"path,omitempty"`
+	// Content is the content of the file
+	Content string `yaml:"content,omitempty"`
+	// HostPath is a path to a file on the host to read the content from.
+	// Relative paths are relative to the current working directory.
+	HostPath string `yaml:"hostPath,omitempty"`
+	// Permissions is the octal file mode to set on the file
+	// Defaults to "0644"
+	Permissions string `yaml:"permissions,omitempty"`
+	// Owner is the "user:group" to own the file
+	// Defaults to "root:root"
+	Owner string `yaml:"owner,omitempty"`
+}
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -184,6 +435,34 @@ type PatchJSON6902 struct {
 	Kind    string `yaml:"kind"`
 	// Patch should contain the contents of the json patch as a string
 	Patch string `yaml:"patch"`
+	// Nodes limits the patch to the matching nodes, if set.
+	// This is only supported for cluster-level patches.
+	Nodes *NodeSelector `yaml:"nodes,omitempty"`
+}
+
+// SelectedPatch is a merge patch applied only to the nodes matching Nodes
+type SelectedPatch struct {
+	// Nodes selects the nodes to patch
+	Nodes NodeSelector `yaml:"nodes"`
+	// Patch should contain the contents of the merge patch as a string
+	Patch string `yaml:"patch"`
+}
+
+// NodeSelector selects nodes from Cluster.Nodes.
+// A node matches if it matches every field that is set, and a field matches
+// if any of its entries match. An empty selector matches every node.
+// In yaml this looks like:
+//  roles: ["worker"]
+//  indexes: [1, 2]
+//  names: ["*-worker*"]
+type NodeSelector struct {
+	// Roles matches nodes with any of these roles
+	Roles []NodeRole `yaml:"roles,omitempty"`
+	// Indexes matches nodes by their (zero based) index in Cluster.Nodes
+	Indexes []int32 `yaml:"indexes,omitempty"`
+	// Names matches nodes whose name (eg "kind-worker2") matches any of these
+	// shell patterns, see https://golang.org/pkg/path/#Match
+	Names []string `yaml:"names,omitempty"`
 }
 
 /*
@@ -236,6 +515,20 @@ type PortMapping struct {
 	Protocol PortMappingProtocol `yaml:"protocol,omitempty"`
 }
 
+// Ingress configures the host ports forwarded to an ingress controller
+// running on a node
+type Ingress struct {
+	// HTTPPort is the host port forwarded to the node's port 80
+	// Defaults to 80
+	HTTPPort int32 `yaml:"httpPort,omitempty"`
+	// HTTPSPort is the host port forwarded to the node's port 443
+	// Defaults to 443
+	HTTPSPort int32 `yaml:"httpsPort,omitempty"`
+	// ListenAddress is the host address the ports are bound to
+	// Defaults to all addresses
+	ListenAddress string `yaml:"listenAddress,omitempty"`
+}
+
 // MountPropagation represents an "enum" for mount propagation options,
 // see also Mount.
 type MountPropagation string
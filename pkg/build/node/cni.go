@@ -20,7 +20,7 @@ package node
 The default CNI manifest and images are our own tiny kindnet
 */
 
-var defaultCNIImages = []string{"kindest/kindnetd:0.5.4"}
+var defaultCNIImages = []string{"kindest/kindnetd:0.6.0"}
 
 const defaultCNIManifest = `
 # kindnetd networking manifest
@@ -43,6 +43,15 @@ rules:
       - ""
     resources:
       - nodes
+      - pods
+      - namespaces
+    verbs:
+      - list
+      - watch
+  - apiGroups:
+      - networking.k8s.io
+    resources:
+      - networkpolicies
     verbs:
       - list
       - watch
@@ -93,7 +102,7 @@ spec:
       serviceAccountName: kindnet
       containers:
       - name: kindnet-cni
-        image: kindest/kindnetd:0.5.4
+        image: kindest/kindnetd:0.6.0
         env:
         - name: HOST_IP
           valueFrom:
@@ -105,6 +114,8 @@ spec:
               fieldPath: status.podIP
         - name: POD_SUBNET
           value: {{ .PodSubnet }}
+        - name: NETWORK_POLICY
+          value: "{{ .NetworkPolicy }}"
         volumeMounts:
         - name: cni-cfg
           mountPath: /etc/cni/net.d
@@ -114,6 +125,24 @@ spec:
         - name: lib-modules
           mountPath: /lib/modules
           readOnly: true
+        - name: cni-plugins
+          mountPath: /etc/kindnet/cni-plugins
+          readOnly: true
+        - name: cni-bin
+          mountPath: /opt/cni/bin
+          readOnly: true
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 19080
+          initialDelaySeconds: 30
+          periodSeconds: 10
+          failureThreshold: 6
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: 19080
+          periodSeconds: 5
         resources:
           requests:
             cpu: "100m"
@@ -136,5 +165,12 @@ spec:
       - name: lib-modules
         hostPath:
           path: /lib/modules
+      - name: cni-plugins
+        configMap:
+          name: kindnet-cni-plugins
+          optional: true
+      - name: cni-bin
+        hostPath:
+          path: /opt/cni/bin
 ---
 `
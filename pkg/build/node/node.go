@@ -430,6 +430,14 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 	// all builds should install the default CNI images from the above manifest currently
 	requiredImages = append(requiredImages, defaultCNIImages...)
 
+	// write the service load balancer manifest, which is only installed
+	// if enabled in the cluster config
+	// NOTE: its image is not pre-pulled, the nodes pull it when it is enabled
+	if err := writeManifest(cmder, defaultServiceLBManifestLocation, defaultServiceLBManifest); err != nil {
+		c.logger.Errorf("Image build Failed! Failed write default service load balancer Manifest: %v", err)
+		return err
+	}
+
 	// for v1.12.0+ we support a nicer storage driver
 	if ver.LessThan(version.MustParseSemantic("v1.12.0")) {
 		// otherwise, we must use something built in and simpler, which is
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+/*
+The default service load balancer manifest uses our own tiny servicelb image
+*/
+
+const defaultServiceLBManifest = `
+# servicelb LoadBalancer services manifest
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: kind-servicelb
+rules:
+  - apiGroups:
+      - ""
+    resources:
+      - services
+      - nodes
+    verbs:
+      - list
+      - watch
+  - apiGroups:
+      - ""
+    resources:
+      - services/status
+    verbs:
+      - update
+  - apiGroups:
+      - coordination.k8s.io
+    resources:
+      - leases
+    verbs:
+      - get
+      - create
+      - update
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: kind-servicelb
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: kind-servicelb
+subjects:
+- kind: ServiceAccount
+  name: kind-servicelb
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kind-servicelb
+  namespace: kube-system
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: kind-servicelb
+  namespace: kube-system
+  labels:
+    tier: node
+    app: kind-servicelb
+    k8s-app: kind-servicelb
+spec:
+  selector:
+    matchLabels:
+      app: kind-servicelb
+  template:
+    metadata:
+      labels:
+        tier: node
+        app: kind-servicelb
+        k8s-app: kind-servicelb
+    spec:
+      hostNetwork: true
+      tolerations:
+      - operator: Exists
+        effect: NoSchedule
+      serviceAccountName: kind-servicelb
+      containers:
+      - name: servicelb
+        image: kindest/servicelb:0.1.0
+        env:
+        - name: HOST_IP
+          valueFrom:
+            fieldRef:
+              fieldPath: status.hostIP
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: ADDRESS_POOL
+          value: "{{ .AddressPool }}"
+        resources:
+          requests:
+            cpu: "50m"
+            memory: "30Mi"
+          limits:
+            cpu: "50m"
+            memory: "30Mi"
+        securityContext:
+          privileged: false
+          capabilities:
+            add: ["NET_ADMIN"]
+---
+`
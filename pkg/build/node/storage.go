@@ -22,13 +22,18 @@ NOTE: we have customized it in the following ways:
 - storage is under /var instead of /opt
 - debian-base is used as the helper image (k8s already ships this upstream as the base for many images) instead of busybox
 - schedule to "master" kubeadm nodes (control-plane host)
-- install as the default storage class
+- install as the default storage class, unless disabled in the cluster config
+- the volume directory is configurable in the cluster config
+- volumes backed by a persistent host directory are named by PVC namespace
+  and name, so that they are reused by a recreated cluster, this requires a
+  newer provisioner which is only used (and pulled at cluster creation) then
 */
 
 var defaultStorageImages = []string{"rancher/local-path-provisioner:v0.0.11", "k8s.gcr.io/debian-base:v2.0.0"}
 
 const defaultStorageManifest = `
 # kind customized https://github.com/rancher/local-path-provisioner manifest
+# would you kindly template this file
 apiVersion: v1
 kind: Namespace
 metadata:
@@ -46,7 +51,7 @@ metadata:
   name: local-path-provisioner-role
 rules:
 - apiGroups: [""]
-  resources: ["nodes", "persistentvolumeclaims"]
+  resources: ["nodes", "persistentvolumeclaims"{{ if .Persistent }}, "configmaps", "pods/log"{{ end }}]
   verbs: ["get", "list", "watch"]
 - apiGroups: [""]
   resources: ["endpoints", "persistentvolumes", "pods"]
@@ -95,14 +100,16 @@ spec:
       serviceAccountName: local-path-provisioner-service-account
       containers:
       - name: local-path-provisioner
-        image: rancher/local-path-provisioner:v0.0.11
+        image: rancher/local-path-provisioner:{{ if .Persistent }}v0.0.26{{ else }}v0.0.11{{ end }}
         imagePullPolicy: IfNotPresent
         command:
         - local-path-provisioner
         - --debug
         - start
+        {{- if not .Persistent }}
         - --helper-image
         - k8s.gcr.io/debian-base:v2.0.0
+        {{- end }}
         - --config
         - /etc/config/config.json
         volumeMounts:
@@ -113,6 +120,10 @@ spec:
           valueFrom:
             fieldRef:
               fieldPath: metadata.namespace
+        {{- if .Persistent }}
+        - name: CONFIG_MOUNT_PATH
+          value: /etc/config/
+        {{- end }}
       volumes:
         - name: config-volume
           configMap:
@@ -124,8 +135,13 @@ metadata:
   namespace: kube-system
   name: standard
   annotations:
-    storageclass.kubernetes.io/is-default-class: "true"
+    storageclass.kubernetes.io/is-default-class: "{{ .DefaultClass }}"
 provisioner: rancher.io/local-path
+{{- if .Persistent }}
+parameters:
+  # name volumes by PVC so that a recreated cluster reuses them
+  pathPattern: "{{ "{{ .PVC.Namespace }}/{{ .PVC.Name }}" }}"
+{{- end }}
 volumeBindingMode: WaitForFirstConsumer
 reclaimPolicy: Delete
 ---
@@ -140,10 +156,30 @@ data:
                 "nodePathMap":[
                 {
                         "node":"DEFAULT_PATH_FOR_NON_LISTED_NODES",
-                        "paths":["/var/local-path-provisioner"]
+                        "paths":["{{ .LocalPath }}"]
                 }
                 ]
         }
+{{- if .Persistent }}
+  setup: |-
+    #!/bin/sh
+    set -eu
+    mkdir -m 0777 -p "$VOL_DIR"
+  teardown: |-
+    #!/bin/sh
+    set -eu
+    rm -rf "$VOL_DIR"
+  helperPod.yaml: |-
+    apiVersion: v1
+    kind: Pod
+    metadata:
+      name: helper-pod
+    spec:
+      containers:
+      - name: helper-pod
+        image: k8s.gcr.io/debian-base:v2.0.0
+        imagePullPolicy: IfNotPresent
+{{- end }}
 `
 
 // legacy default storage class for older than Kubernetes v1.12.0
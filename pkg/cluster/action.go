@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/log"
+
+	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+// These are the names of the built-in Provider.Create actions, in the order
+// they run. Extra actions may be added relative to these, see
+// CreateWithActionsBefore, CreateWithActionsAfter and CreateWithSkippedActions
+const (
+	// ActionWriteFiles writes the files from the cluster config into the nodes
+	ActionWriteFiles = internalcreate.WriteFilesAction
+	// ActionLoadBalancer configures the external load balancer, if any
+	ActionLoadBalancer = internalcreate.LoadBalancerAction
+	// ActionConfig writes the kubeadm config and patches containerd config
+	ActionConfig = internalcreate.ConfigAction
+	// ActionKubeadmInit runs kubeadm init on the bootstrap control plane
+	ActionKubeadmInit = internalcreate.KubeadmInitAction
+	// ActionInstallCNI installs the default CNI
+	ActionInstallCNI = internalcreate.InstallCNIAction
+	// ActionInstallStorage installs the default StorageClass
+	ActionInstallStorage = internalcreate.InstallStorageAction
+	// ActionInstallServiceLB installs the LoadBalancer service controller,
+	// if enabled in the config
+	ActionInstallServiceLB = internalcreate.InstallServiceLBAction
+	// ActionKubeadmJoin runs kubeadm join on the remaining nodes
+	ActionKubeadmJoin = internalcreate.KubeadmJoinAction
+	// ActionApplyManifests applies the manifests from the cluster config
+	ActionApplyManifests = internalcreate.ApplyManifestsAction
+	// ActionWaitForReady waits for the control plane to be ready
+	ActionWaitForReady = internalcreate.WaitForReadyAction
+)
+
+// Action is a user supplied step of Provider.Create, run after the node
+// containers are created
+type Action interface {
+	// Name returns a short name for the action, used to report progress
+	// and to record completed actions for resuming, it must be unique and
+	// cannot be the name of a built-in action
+	Name() string
+	// Execute runs the action, if an error is returned cluster creation
+	// will be aborted
+	Execute(ctx *ActionContext) error
+}
+
+// NewAction returns an Action with name that calls execute
+func NewAction(name string, execute func(ctx *ActionContext) error) Action {
+	return &funcAction{
+		name:    name,
+		execute: execute,
+	}
+}
+
+type funcAction struct {
+	name    string
+	execute func(ctx *ActionContext) error
+}
+
+func (a *funcAction) Name() string {
+	return a.name
+}
+
+func (a *funcAction) Execute(ctx *ActionContext) error {
+	return a.execute(ctx)
+}
+
+// ActionContext is data supplied to all user supplied Actions
+type ActionContext struct {
+	ctx *actions.ActionContext
+}
+
+// ClusterName returns the name of the cluster being created
+func (c *ActionContext) ClusterName() string {
+	return c.ctx.ClusterContext.Name()
+}
+
+// Logger returns the logger the cluster is being created with
+func (c *ActionContext) Logger() log.Logger {
+	return c.ctx.Logger
+}
+
+// Nodes returns the list of cluster nodes, this is a cached call
+func (c *ActionContext) Nodes() ([]nodes.Node, error) {
+	return c.ctx.Nodes()
+}
+
+// StartStatus starts a new phase of the create status output, any previous
+// phase is ended successfully
+func (c *ActionContext) StartStatus(status string) {
+	c.ctx.Status.Start(status)
+}
+
+// EndStatus ends the current phase of the create status output
+func (c *ActionContext) EndStatus(success bool) {
+	c.ctx.Status.End(success)
+}
+
+// actionAdapter adapts an Action to the internal actions.Action
+type actionAdapter struct {
+	action Action
+}
+
+func (a actionAdapter) Execute(ctx *actions.ActionContext) error {
+	return a.action.Execute(&ActionContext{ctx: ctx})
+}
+
+func toNamedActions(userActions []Action) []internalcreate.NamedAction {
+	named := make([]internalcreate.NamedAction, len(userActions))
+	for i, a := range userActions {
+		named[i] = internalcreate.NamedAction{
+			Name:   a.Name(),
+			Action: actionAdapter{action: a},
+		}
+	}
+	return named
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// CollectLogsOption is a Provider.CollectLogs option
+type CollectLogsOption interface {
+	apply(*internallogs.Options) error
+}
+
+type collectLogsOptionAdapter func(*internallogs.Options) error
+
+func (c collectLogsOptionAdapter) apply(o *internallogs.Options) error {
+	return c(o)
+}
+
+// CollectLogsComponents returns the components that can be selected
+// with CollectLogsWithComponents
+func CollectLogsComponents() []string {
+	return internallogs.Components()
+}
+
+// CollectLogsSince limits the collected logs to those written in the last
+// since, if zero (the default) all logs are collected
+func CollectLogsSince(since time.Duration) CollectLogsOption {
+	return collectLogsOptionAdapter(func(o *internallogs.Options) error {
+		if since < 0 {
+			return errors.Errorf("invalid negative since: %v", since)
+		}
+		o.Since = since
+		return nil
+	})
+}
+
+// CollectLogsWithNodes limits the collected logs to the nodes with these
+// names, by default all nodes are collected
+func CollectLogsWithNodes(names ...string) CollectLogsOption {
+	return collectLogsOptionAdapter(func(o *internallogs.Options) error {
+		o.Nodes = append(o.Nodes, names...)
+		return nil
+	})
+}
+
+// CollectLogsWithComponents limits the collected logs to these components,
+// see CollectLogsComponents, by default all components are collected
+func CollectLogsWithComponents(components ...string) CollectLogsOption {
+	return collectLogsOptionAdapter(func(o *internallogs.Options) error {
+		for _, c := range components {
+			known := false
+			for _, k := range internallogs.Components() {
+				if c == k {
+					known = true
+					break
+				}
+			}
+			if !known {
+				return errors.Errorf("unknown log component %q, must be one of %v", c, internallogs.Components())
+			}
+		}
+		o.Components = append(o.Components, components...)
+		return nil
+	})
+}
+
+// CollectLogsMaxSize limits each collected file to its last maxSize bytes,
+// if zero (the default) files are not limited
+func CollectLogsMaxSize(maxSize int64) CollectLogsOption {
+	return collectLogsOptionAdapter(func(o *internallogs.Options) error {
+		if maxSize < 0 {
+			return errors.Errorf("invalid negative max size: %d", maxSize)
+		}
+		o.MaxSize = maxSize
+		return nil
+	})
+}
@@ -89,6 +89,20 @@ func CreateWithRetain(retain bool) CreateOption {
 	})
 }
 
+// CreateWithResume resumes a previous, failed attempt at creating the
+// cluster with retained nodes (see CreateWithRetain), skipping node creation
+// and all create actions that already completed
+// The same configuration as the failed attempt must be used, resuming with a
+// different configuration fails. Nodes where kubeadm init or join failed are
+// reset with kubeadm before it is retried, and nodes are always retained if
+// the resumed create fails
+func CreateWithResume(resume bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Resume = resume
+		return nil
+	})
+}
+
 // CreateWithWaitForReady configures a maximum wait time for the control plane
 // node(s) to be ready. By default no waiting is performed
 func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
@@ -133,3 +147,45 @@ func CreateWithDisplaySalutation(displaySalutation bool) CreateOption {
 		return nil
 	})
 }
+
+// CreateWithActionsBefore adds userActions to run immediately before the
+// built-in action named action (see ActionLoadBalancer etc.)
+// Actions added this way run even if the built-in action is skipped
+func CreateWithActionsBefore(action string, userActions ...Action) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if o.ActionsBefore == nil {
+			o.ActionsBefore = map[string][]internalcreate.NamedAction{}
+		}
+		o.ActionsBefore[action] = append(o.ActionsBefore[action], toNamedActions(userActions)...)
+		return nil
+	})
+}
+
+// CreateWithActionsAfter adds userActions to run immediately after the
+// built-in action named action (see ActionLoadBalancer etc.)
+// Actions added this way run even if the built-in action is skipped
+func CreateWithActionsAfter(action string, userActions ...Action) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if o.ActionsAfter == nil {
+			o.ActionsAfter = map[string][]internalcreate.NamedAction{}
+		}
+		o.ActionsAfter[action] = append(o.ActionsAfter[action], toNamedActions(userActions)...)
+		return nil
+	})
+}
+
+// CreateWithSkippedActions disables the built-in actions named actions
+// (see ActionLoadBalancer etc.)
+// This generally shouldn't be used unless the skipped steps are replaced
+// by equivalent user supplied actions
+func CreateWithSkippedActions(actions ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if o.SkipActions == nil {
+			o.SkipActions = map[string]bool{}
+		}
+		for _, action := range actions {
+			o.SkipActions[action] = true
+		}
+		return nil
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
@@ -87,3 +88,25 @@ func (ac *ActionContext) Nodes() ([]nodes.Node, error) {
 	ac.cache.setNodes(n)
 	return n, nil
 }
+
+// NodeConfigs returns the config each of the cluster's nodes was created
+// from, keyed by node name
+func (ac *ActionContext) NodeConfigs() map[string]*config.Node {
+	out := make(map[string]*config.Node, len(ac.Config.Nodes))
+	for name, i := range ac.NodeIndexes() {
+		out[name] = &ac.Config.Nodes[i]
+	}
+	return out
+}
+
+// NodeIndexes returns the index in Config.Nodes of each of the cluster's
+// nodes, keyed by node name
+func (ac *ActionContext) NodeIndexes() map[string]int {
+	// nodes are named by the provider in config order, see provision
+	namer := common.MakeNodeNamer(ac.ClusterContext.Name())
+	out := make(map[string]int, len(ac.Config.Nodes))
+	for i := range ac.Config.Nodes {
+		out[namer(string(ac.Config.Nodes[i].Role))] = i
+	}
+	return out
+}
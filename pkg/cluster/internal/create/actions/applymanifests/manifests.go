@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package applymanifests implements the action to apply user supplied
+// manifests after the cluster is created
+package applymanifests
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// rolloutTimeout is how long we wait for each workload to roll out
+const rolloutTimeout = "5m"
+
+type action struct{}
+
+// NewAction returns a new action for applying the configured manifests
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
+	ctx.Status.Start("Applying manifests 📃")
+	defer func() { ctx.Status.EndWithError(err) }()
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// apply the manifests in order
+	for i, m := range ctx.Config.Manifests {
+		if err := Apply(node, m); err != nil {
+			return errors.Wrapf(err, "manifest %d", i)
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Apply applies the manifest m to the cluster from node, reading it from
+// the host if necessary, and waits for it to roll out if requested
+func Apply(node nodes.Node, m config.Manifest) error {
+	manifest, err := readManifest(m)
+	if err != nil {
+		return errors.Wrap(err, "failed to read manifest")
+	}
+	if err := applyManifest(node, manifest); err != nil {
+		return errors.Wrap(err, "failed to apply manifest")
+	}
+	if !m.WaitForRollout {
+		return nil
+	}
+	if err := waitForRollout(node, manifest); err != nil {
+		return errors.Wrap(err, "failed waiting for manifest to roll out")
+	}
+	return nil
+}
+
+// readManifest returns the contents of m, reading from the host if necessary
+func readManifest(m config.Manifest) (string, error) {
+	if m.Content != "" {
+		return m.Content, nil
+	}
+	info, err := os.Stat(m.Path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if !info.IsDir() {
+		raw, err := ioutil.ReadFile(m.Path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		return string(raw), nil
+	}
+	// like kubectl apply -f dir, only consider manifest files directly
+	// in the directory
+	entries, err := ioutil.ReadDir(m.Path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	files := []string{}
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, entry.Name())
+			}
+		}
+	}
+	sort.Strings(files)
+	documents := []string{}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(filepath.Join(m.Path, file))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		documents = append(documents, string(raw))
+	}
+	return strings.Join(documents, "\n---\n"), nil
+}
+
+// applyManifest applies manifest to the cluster from node
+func applyManifest(node nodes.Node, manifest string) error {
+	cmd := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"apply", "-f", "-",
+	).SetStdin(strings.NewReader(manifest))
+	return cmd.Run()
+}
+
+// waitForRollout waits for all workloads in manifest to finish rolling out
+func waitForRollout(node nodes.Node, manifest string) error {
+	workloads, err := rolloutWorkloads(manifest)
+	if err != nil {
+		return err
+	}
+	for _, w := range workloads {
+		cmd := node.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"rollout", "status",
+			"--namespace", w.namespace,
+			"--timeout", rolloutTimeout,
+			w.kind+"/"+w.name,
+		)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type workload struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// rolloutWorkloads returns the workloads in manifest that kubectl rollout
+// status can wait for
+func rolloutWorkloads(manifest string) ([]workload, error) {
+	workloads := []workload{}
+	d := yaml.NewDecoder(bytes.NewReader([]byte(manifest)))
+	for {
+		obj := struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}{}
+		if err := d.Decode(&obj); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to parse manifest")
+		}
+		switch obj.Kind {
+		case "Deployment", "DaemonSet", "StatefulSet":
+		default:
+			continue
+		}
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		workloads = append(workloads, workload{
+			kind:      strings.ToLower(obj.Kind),
+			namespace: namespace,
+			name:      obj.Metadata.Name,
+		})
+	}
+	return workloads, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applymanifests
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRolloutWorkloads(t *testing.T) {
+	t.Parallel()
+	manifest := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: test
+---
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+`
+	workloads, err := rolloutWorkloads(manifest)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []workload{
+		{kind: "deployment", namespace: "test", name: "operator"},
+		{kind: "daemonset", namespace: "default", name: "agent"},
+	}, workloads)
+
+	_, err = rolloutWorkloads("kind: [")
+	assert.ExpectError(t, true, err)
+}
@@ -19,7 +19,11 @@ package config
 
 import (
 	"bytes"
+	"fmt"
+	"path"
+	"sort"
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
@@ -31,6 +35,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 )
 
 // Action implements action for creating the node config files
@@ -42,9 +47,9 @@ func NewAction() actions.Action {
 }
 
 // Execute runs the action
-func (a *Action) Execute(ctx *actions.ActionContext) error {
+func (a *Action) Execute(ctx *actions.ActionContext) (err error) {
 	ctx.Status.Start("Writing configuration 📜")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	allNodes, err := ctx.Nodes()
 	if err != nil {
@@ -77,15 +82,32 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
 		ControlPlane:         true,
 		IPv6:                 ctx.Config.Networking.IPFamily == "ipv6",
+		DualStack:            ctx.Config.Networking.IPFamily == "dual",
+		FeatureGates:         ctx.Config.FeatureGates,
+		RuntimeConfig:        ctx.Config.RuntimeConfig,
+		KubeProxyMode:        string(ctx.Config.Networking.KubeProxyMode),
 	}
 
+	// track which patches matched a document on each node, so we can warn
+	// once about patches that did not match on any of the nodes they select
+	nodeIndexes := ctx.NodeIndexes()
+	var patchMatchesMu sync.Mutex
+	patchMatches := []map[string]bool{}
+
 	kubeadmConfigPlusPatches := func(node nodes.Node, data kubeadm.ConfigData) func() error {
 		return func() error {
-			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node)
+			nodeIndex, ok := nodeIndexes[node.String()]
+			if !ok {
+				nodeIndex = -1
+			}
+			kubeadmConfig, matched, err := getKubeadmConfig(ctx.Config, data, node, nodeIndex)
 			if err != nil {
 				// TODO(bentheelder): logging here
 				return errors.Wrap(err, "failed to generate kubeadm config content")
 			}
+			patchMatchesMu.Lock()
+			patchMatches = append(patchMatches, matched)
+			patchMatchesMu.Unlock()
 
 			ctx.Logger.V(2).Info("Using kubeadm config:\n" + kubeadmConfig)
 			return writeKubeadmConfig(kubeadmConfig, node)
@@ -98,10 +120,19 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	// record the effective cluster config on the control plane nodes
+	// for debugging, eg with `kind export logs`
+	clusterConfig, err := encoding.Dump(ctx.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster config")
+	}
 	for _, node := range controlPlanes {
 		node := node             // capture loop variable
 		configData := configData // copy config data
 		fns = append(fns, kubeadmConfigPlusPatches(node, configData))
+		fns = append(fns, func() error {
+			return writeClusterConfig(string(clusterConfig), node)
+		})
 	}
 
 	// then create the kubeadm join config for the worker nodes if any
@@ -125,61 +156,117 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// if we have containerd config, patch all the nodes concurrently
-	if len(ctx.Config.ContainerdConfigPatches) > 0 || len(ctx.Config.ContainerdConfigPatchesJSON6902) > 0 {
-		// we only want to patch kubernetes nodes
-		// this is a cheap workaround to re-use the already listed
-		// workers + control planes
-		kubeNodes := append([]nodes.Node{}, controlPlanes...)
-		kubeNodes = append(kubeNodes, workers...)
-		fns := make([]func() error, len(kubeNodes))
-		for i, node := range kubeNodes {
-			node := node // capture loop variable
-			fns[i] = func() error {
-				// read and patch the config
-				const containerdConfigPath = "/etc/containerd/config.toml"
-				var buff bytes.Buffer
-				if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
-					return errors.Wrap(err, "failed to read containerd config from node")
-				}
-				patched, err := patch.TOML(buff.String(), ctx.Config.ContainerdConfigPatches, ctx.Config.ContainerdConfigPatchesJSON6902)
-				if err != nil {
-					return errors.Wrap(err, "failed to patch contianerd config")
-				}
-				if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
-					return errors.Wrap(err, "failed to write patched containerd config")
-				}
-				// restart containerd now that we've re-configured it
-				// skip if the systemd (also the containerd) is not running
-				if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
-					return errors.Wrap(err, "failed to restart containerd after patching config")
-				}
-				return nil
-			}
+	// we only want to patch kubernetes nodes
+	// this is a cheap workaround to re-use the already listed
+	// workers + control planes
+	kubeNodes := append([]nodes.Node{}, controlPlanes...)
+	kubeNodes = append(kubeNodes, workers...)
+	nodeConfigs := ctx.NodeConfigs()
+	fns = []func() error{}
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		var nodePatches, nodePatchesJSON6902 []string
+		if nodeConfig, ok := nodeConfigs[node.String()]; ok {
+			nodePatches = nodeConfig.ContainerdConfigPatches
+			nodePatchesJSON6902 = nodeConfig.ContainerdConfigPatchesJSON6902
 		}
-		if err := errors.UntilErrorConcurrent(fns); err != nil {
-			return err
+		if len(ctx.Config.ContainerdConfigPatches) == 0 && len(ctx.Config.ContainerdConfigPatchesJSON6902) == 0 &&
+			len(nodePatches) == 0 && len(nodePatchesJSON6902) == 0 {
+			continue
 		}
+		fns = append(fns, func() error {
+			return patchContainerdConfig(ctx.Config, node, nodePatches, nodePatchesJSON6902)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
 	}
 
 	// mark success
 	ctx.Status.End(true)
+
+	// warn about patches that are likely mistakes
+	for _, name := range unmatchedPatches(patchMatches) {
+		ctx.Logger.Warnf("kubeadm config patch %s did not match any generated document", name)
+	}
+	return nil
+}
+
+// unmatchedPatches returns the sorted names of the patches that did not
+// match a document on any node, given whether each patch selecting a node
+// matched a document on it for each node
+func unmatchedPatches(patchMatches []map[string]bool) []string {
+	matchedAny := map[string]bool{}
+	for _, matched := range patchMatches {
+		for name, ok := range matched {
+			matchedAny[name] = matchedAny[name] || ok
+		}
+	}
+	names := []string{}
+	for name, ok := range matchedAny {
+		if !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// patchContainerdConfig patches the containerd config on node with the
+// cluster-level patches followed by the node-level patches, restarting
+// containerd if the config changed
+func patchContainerdConfig(cfg *config.Cluster, node nodes.Node, nodePatches, nodePatchesJSON6902 []string) error {
+	// read and patch the config
+	const containerdConfigPath = "/etc/containerd/config.toml"
+	var buff bytes.Buffer
+	if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrap(err, "failed to read containerd config from node")
+	}
+	// normalize the original config so we can tell if patching changed it
+	original, err := patch.TOML(buff.String(), nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse containerd config")
+	}
+	patched, err := patch.TOML(original, cfg.ContainerdConfigPatches, cfg.ContainerdConfigPatchesJSON6902)
+	if err != nil {
+		return errors.Wrap(err, "failed to patch contianerd config")
+	}
+	patched, err = patch.TOML(patched, nodePatches, nodePatchesJSON6902)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply node patches to containerd config")
+	}
+	if patched == original {
+		return nil
+	}
+	if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
+		return errors.Wrap(err, "failed to write patched containerd config")
+	}
+	// restart containerd now that we've re-configured it
+	// skip if the systemd (also the containerd) is not running
+	if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart containerd after patching config")
+	}
 	return nil
 }
 
 // getKubeadmConfig generates the kubeadm config contents for the cluster
 // by running data through the template and applying patches as needed.
-func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node) (path string, err error) {
+// nodeIndex is the index of the node in cfg.Nodes, or -1 if it is unknown.
+//
+// It also returns whether each patch selecting the node matched a document,
+// keyed by patch name.
+func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node, nodeIndex int) (path string, matched map[string]bool, err error) {
 	kubeVersion, err := nodeutils.KubeVersion(node)
 	if err != nil {
 		// TODO(bentheelder): logging here
-		return "", errors.Wrap(err, "failed to get kubernetes version from node")
+		return "", nil, errors.Wrap(err, "failed to get kubernetes version from node")
 	}
 	data.KubernetesVersion = kubeVersion
 
 	// get the node ip address
 	nodeAddress, nodeAddressIPv6, err := node.IP()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get IP for node")
+		return "", nil, errors.Wrap(err, "failed to get IP for node")
 	}
 
 	data.NodeAddress = nodeAddress
@@ -187,36 +274,42 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.N
 	if cfg.Networking.IPFamily == "ipv6" {
 		data.NodeAddress = nodeAddressIPv6
 	}
+	// dual stack clusters use both, with IPv4 as the primary address
+	if cfg.Networking.IPFamily == "dual" {
+		data.NodeAddressIPv6 = nodeAddressIPv6
+	}
+
+	// label nodes that are set up for ingress
+	if nodeIndex >= 0 && cfg.Nodes[nodeIndex].Ingress != nil {
+		data.NodeLabels = map[string]string{config.IngressReadyLabel: "true"}
+	}
 
 	// generate the config contents
 	cf, err := kubeadm.Config(data)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	clusterPatches, clusterJSONPatches := allPatchesFromConfig(cfg)
 	// apply cluster-level patches first
-	patchedConfig, err := patch.KubeYAML(cf, clusterPatches, clusterJSONPatches)
+	patchedConfig, matched, err := clusterPatchesForNode(cfg, node.String(), nodeIndex).apply(cf)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	// since we only need the last portion of the name,
-	// create namer without a clusterName
-	namer := common.MakeNodeNamer("")
-	for _, inode := range cfg.Nodes {
-		nodeSuffix := namer(string(inode.Role))
-		// if needed, apply current node's patches
-		if strings.HasSuffix(node.String(), nodeSuffix) && (len(inode.KubeadmConfigPatches) > 0 || len(inode.KubeadmConfigPatchesJSON6902) > 0) {
-			patchedConfig, err = patch.KubeYAML(patchedConfig, inode.KubeadmConfigPatches, inode.KubeadmConfigPatchesJSON6902)
-			if err != nil {
-				return "", err
-			}
+	// then, if needed, apply current node's patches
+	if nodeIndex >= 0 {
+		var nodeMatched map[string]bool
+		patchedConfig, nodeMatched, err = nodePatches(cfg, nodeIndex).apply(patchedConfig)
+		if err != nil {
+			return "", nil, err
+		}
+		for name, ok := range nodeMatched {
+			matched[name] = ok
 		}
 	}
 
 	// fix all the patches to have name metadata matching the generated config
-	return removeMetadata(patchedConfig), nil
+	return removeMetadata(patchedConfig), matched, nil
 }
 
 // trims out the metadata.name we put in the config for kustomize matching,
@@ -232,8 +325,128 @@ func removeMetadata(kustomized string) string {
 	)
 }
 
-func allPatchesFromConfig(cfg *config.Cluster) (patches []string, jsonPatches []config.PatchJSON6902) {
-	return cfg.KubeadmConfigPatches, cfg.KubeadmConfigPatchesJSON6902
+// namedPatches are kubeadm config patches to apply together, along with
+// the config field each patch came from for reporting
+type namedPatches struct {
+	patches        []string
+	patchNames     []string
+	jsonPatches    []config.PatchJSON6902
+	jsonPatchNames []string
+}
+
+func (p *namedPatches) addPatch(name string, patch string) {
+	p.patches = append(p.patches, patch)
+	p.patchNames = append(p.patchNames, name)
+}
+
+func (p *namedPatches) addJSONPatch(name string, patch config.PatchJSON6902) {
+	p.jsonPatches = append(p.jsonPatches, patch)
+	p.jsonPatchNames = append(p.jsonPatchNames, name)
+}
+
+// apply applies the patches to toPatch, returning the result and whether
+// each patch matched a document, keyed by patch name
+func (p *namedPatches) apply(toPatch string) (string, map[string]bool, error) {
+	matched := map[string]bool{}
+	if len(p.patches) == 0 && len(p.jsonPatches) == 0 {
+		return toPatch, matched, nil
+	}
+	patched, unmatched, err := patch.KubeYAMLWithUnmatched(toPatch, p.patches, p.jsonPatches)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, name := range p.patchNames {
+		matched[name] = true
+	}
+	for _, name := range p.jsonPatchNames {
+		matched[name] = true
+	}
+	for _, i := range unmatched.Patches {
+		matched[p.patchNames[i]] = false
+	}
+	for _, i := range unmatched.PatchesJSON6902 {
+		matched[p.jsonPatchNames[i]] = false
+	}
+	return patched, matched, nil
+}
+
+// clusterPatchesForNode returns the cluster-level patches selecting the node,
+// merge patches are applied before JSON 6902 patches, each in the order listed
+func clusterPatchesForNode(cfg *config.Cluster, name string, index int) *namedPatches {
+	p := &namedPatches{}
+	for i, patch := range cfg.KubeadmConfigPatches {
+		p.addPatch(fmt.Sprintf("kubeadmConfigPatches[%d]", i), patch)
+	}
+	for i, patch := range cfg.KubeadmConfigSelectedPatches {
+		if selectsNode(&patch.Nodes, cfg, name, index) {
+			p.addPatch(fmt.Sprintf("kubeadmConfigSelectedPatches[%d]", i), patch.Patch)
+		}
+	}
+	for i, patch := range cfg.KubeadmConfigPatchesJSON6902 {
+		if patch.Nodes == nil || selectsNode(patch.Nodes, cfg, name, index) {
+			p.addJSONPatch(fmt.Sprintf("kubeadmConfigPatchesJSON6902[%d]", i), patch)
+		}
+	}
+	return p
+}
+
+// nodePatches returns the node-level patches for cfg.Nodes[index]
+func nodePatches(cfg *config.Cluster, index int) *namedPatches {
+	p := &namedPatches{}
+	n := &cfg.Nodes[index]
+	for i, patch := range n.KubeadmConfigPatches {
+		p.addPatch(fmt.Sprintf("nodes[%d].kubeadmConfigPatches[%d]", index, i), patch)
+	}
+	for i, patch := range n.KubeadmConfigPatchesJSON6902 {
+		p.addJSONPatch(fmt.Sprintf("nodes[%d].kubeadmConfigPatchesJSON6902[%d]", index, i), patch)
+	}
+	return p
+}
+
+// selectsNode returns true if the selector matches the node with the given
+// name and index in cfg.Nodes, a node with an unknown index never matches
+func selectsNode(s *config.NodeSelector, cfg *config.Cluster, name string, index int) bool {
+	if index < 0 || index >= len(cfg.Nodes) {
+		return false
+	}
+	if len(s.Roles) > 0 {
+		matched := false
+		for _, role := range s.Roles {
+			matched = matched || role == cfg.Nodes[index].Role
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(s.Indexes) > 0 {
+		matched := false
+		for _, i := range s.Indexes {
+			matched = matched || int(i) == index
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(s.Names) > 0 {
+		matched := false
+		for _, pattern := range s.Names {
+			// patterns are validated with the config
+			m, _ := path.Match(pattern, name)
+			matched = matched || m
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// writeClusterConfig writes the effective cluster config in the specified node
+func writeClusterConfig(clusterConfig string, node nodes.Node) error {
+	if err := nodeutils.WriteFile(node, "/kind/cluster-config.yaml", clusterConfig); err != nil {
+		return errors.Wrap(err, "failed to copy cluster config to node")
+	}
+	return nil
 }
 
 // writeKubeadmConfig writes the kubeadm configuration in the specified node
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestClusterPatchesForNode(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole},
+			{Role: config.WorkerRole},
+		},
+		KubeadmConfigPatches: []string{"all"},
+		KubeadmConfigSelectedPatches: []config.SelectedPatch{
+			{Patch: "workers", Nodes: config.NodeSelector{Roles: []config.NodeRole{config.WorkerRole}}},
+			{Patch: "second worker", Nodes: config.NodeSelector{Roles: []config.NodeRole{config.WorkerRole}, Indexes: []int32{2}}},
+			{Patch: "control-plane by name", Nodes: config.NodeSelector{Names: []string{"*-control-plane*"}}},
+		},
+		KubeadmConfigPatchesJSON6902: []config.PatchJSON6902{
+			{Patch: "all json"},
+			{Patch: "first node json", Nodes: &config.NodeSelector{Indexes: []int32{0}}},
+		},
+	}
+	cases := []struct {
+		Name                string
+		NodeName            string
+		NodeIndex           int
+		ExpectedPatches     []string
+		ExpectedJSONPatches []string
+	}{
+		{
+			Name:                "control-plane",
+			NodeName:            "kind-control-plane",
+			NodeIndex:           0,
+			ExpectedPatches:     []string{"all", "control-plane by name"},
+			ExpectedJSONPatches: []string{"all json", "first node json"},
+		},
+		{
+			Name:                "first worker",
+			NodeName:            "kind-worker",
+			NodeIndex:           1,
+			ExpectedPatches:     []string{"all", "workers"},
+			ExpectedJSONPatches: []string{"all json"},
+		},
+		{
+			Name:                "second worker",
+			NodeName:            "kind-worker2",
+			NodeIndex:           2,
+			ExpectedPatches:     []string{"all", "workers", "second worker"},
+			ExpectedJSONPatches: []string{"all json"},
+		},
+		{
+			Name:                "unknown node",
+			NodeName:            "kind-worker3",
+			NodeIndex:           -1,
+			ExpectedPatches:     []string{"all"},
+			ExpectedJSONPatches: []string{"all json"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p := clusterPatchesForNode(cfg, tc.NodeName, tc.NodeIndex)
+			jsonPatches := []string{}
+			for _, patch := range p.jsonPatches {
+				jsonPatches = append(jsonPatches, patch.Patch)
+			}
+			assert.DeepEqual(t, tc.ExpectedPatches, p.patches)
+			assert.DeepEqual(t, tc.ExpectedJSONPatches, jsonPatches)
+		})
+	}
+}
+
+func TestUnmatchedPatches(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name         string
+		PatchMatches []map[string]bool
+		Expected     []string
+	}{
+		{
+			Name:     "no nodes",
+			Expected: []string{},
+		},
+		{
+			Name: "matched on every node",
+			PatchMatches: []map[string]bool{
+				{"kubeadmConfigPatches[0]": true},
+				{"kubeadmConfigPatches[0]": true},
+			},
+			Expected: []string{},
+		},
+		{
+			Name: "init config patch only matches the control plane",
+			PatchMatches: []map[string]bool{
+				{"kubeadmConfigPatches[0]": true},
+				{"kubeadmConfigPatches[0]": false},
+				{"kubeadmConfigPatches[0]": false},
+			},
+			Expected: []string{},
+		},
+		{
+			Name: "unmatched on every selected node",
+			PatchMatches: []map[string]bool{
+				{"kubeadmConfigPatches[0]": true, "kubeadmConfigSelectedPatches[0]": false},
+				{"kubeadmConfigPatches[0]": true},
+				{"kubeadmConfigPatches[0]": true, "nodes[2].kubeadmConfigPatches[0]": false, "kubeadmConfigSelectedPatches[0]": false},
+			},
+			Expected: []string{"kubeadmConfigSelectedPatches[0]", "nodes[2].kubeadmConfigPatches[0]"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tc.Expected, unmatchedPatches(tc.PatchMatches))
+		})
+	}
+}
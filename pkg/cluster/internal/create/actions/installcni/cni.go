@@ -36,9 +36,9 @@ func NewAction() actions.Action {
 }
 
 // Execute runs the action
-func (a *action) Execute(ctx *actions.ActionContext) error {
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
 	ctx.Status.Start("Installing CNI 🔌")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	allNodes, err := ctx.Nodes()
 	if err != nil {
@@ -73,9 +73,11 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		}
 		var out bytes.Buffer
 		err = t.Execute(&out, &struct {
-			PodSubnet string
+			PodSubnet     string
+			NetworkPolicy bool
 		}{
-			PodSubnet: ctx.Config.Networking.PodSubnet,
+			PodSubnet:     ctx.Config.Networking.PodSubnet,
+			NetworkPolicy: ctx.Config.Networking.EnableNetworkPolicy,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to execute CNI manifest template")
@@ -93,5 +95,10 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 
 	// mark success
 	ctx.Status.End(true)
+
+	// older node images ship a manifest without network policy support
+	if ctx.Config.Networking.EnableNetworkPolicy && !strings.Contains(manifest, "NETWORK_POLICY") {
+		ctx.Logger.Warn("the default CNI manifest in this node image does not support network policy, NetworkPolicies will not be enforced")
+	}
 	return nil
 }
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installservicelb implements the action to install the
+// LoadBalancer service controller
+package installservicelb
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct{}
+
+// NewAction returns a new action for installing the service load balancer
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
+	ctx.Status.Start("Installing LoadBalancer controller ⚖️")
+	defer func() { ctx.Status.EndWithError(err) }()
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// read the manifest from the node
+	var raw bytes.Buffer
+	if err := node.Command("cat", "/kind/manifests/default-servicelb.yaml").SetStdout(&raw).Run(); err != nil {
+		return errors.Wrap(err, "failed to read service load balancer manifest, the node image may be too old to support enableLoadBalancer")
+	}
+
+	t, err := template.New("servicelb-manifest").Parse(raw.String())
+	if err != nil {
+		return errors.Wrap(err, "failed to parse service load balancer manifest template")
+	}
+	var manifest bytes.Buffer
+	err = t.Execute(&manifest, &struct {
+		AddressPool string
+	}{
+		AddressPool: ctx.Config.Networking.LoadBalancerAddressPool,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to execute service load balancer manifest template")
+	}
+
+	// install the manifest
+	if err := node.Command(
+		"kubectl", "apply", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"-f", "-",
+	).SetStdin(strings.NewReader(manifest.String())).Run(); err != nil {
+		return errors.Wrap(err, "failed to install service load balancer")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
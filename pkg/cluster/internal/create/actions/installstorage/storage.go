@@ -21,12 +21,15 @@ package installstorage
 import (
 	"bytes"
 	"strings"
+	"text/template"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/applymanifests"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
@@ -38,9 +41,9 @@ func NewAction() actions.Action {
 }
 
 // Execute runs the action
-func (a *action) Execute(ctx *actions.ActionContext) error {
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
 	ctx.Status.Start("Installing StorageClass 💾")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	allNodes, err := ctx.Nodes()
 	if err != nil {
@@ -54,13 +57,34 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 	node := controlPlanes[0] // kind expects at least one always
 
+	// the user may replace the default storage class entirely
+	if ctx.Config.Storage.Manifest != nil {
+		if err := applymanifests.Apply(node, *ctx.Config.Storage.Manifest); err != nil {
+			return errors.Wrap(err, "failed to install storage manifest")
+		}
+		ctx.Status.End(true)
+		return nil
+	}
+
 	// add the default storage class
-	if err := addDefaultStorage(ctx.Logger, node); err != nil {
+	manifest, err := addDefaultStorage(ctx.Logger, node, &ctx.Config.Storage)
+	if err != nil {
 		return errors.Wrap(err, "failed to add default storage class")
 	}
 
 	// mark success
 	ctx.Status.End(true)
+
+	// older node images ship a manifest without these options
+	if ctx.Config.Storage.NonDefaultStorageClass && !strings.Contains(manifest, ".DefaultClass") {
+		ctx.Logger.Warn("the default storage manifest in this node image does not support nonDefaultStorageClass, it is the default StorageClass")
+	}
+	if ctx.Config.Storage.LocalPath != config.DefaultStorageLocalPath && !strings.Contains(manifest, ".LocalPath") {
+		ctx.Logger.Warn("the default storage manifest in this node image does not support localPath, volumes are created in its default directory")
+	}
+	if ctx.Config.Storage.PersistentHostPath != "" && !strings.Contains(manifest, ".Persistent") {
+		ctx.Logger.Warn("the default storage manifest in this node image does not support persistentHostPath, volumes will not be reused by a recreated cluster")
+	}
 	return nil
 }
 
@@ -68,16 +92,19 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 // we need this for e2es (StatefulSet)
 // newer kind images ship a storage driver manifest
 const defaultStorageManifest = `# host-path based default storage class
+# would you kindly template this file
 apiVersion: storage.k8s.io/v1
 kind: StorageClass
 metadata:
   namespace: kube-system
   name: standard
   annotations:
-    storageclass.kubernetes.io/is-default-class: "true"
+    storageclass.kubernetes.io/is-default-class: "{{ .DefaultClass }}"
 provisioner: kubernetes.io/host-path`
 
-func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
+// addDefaultStorage installs the default storage manifest configured with
+// storage, it returns the manifest before templating
+func addDefaultStorage(logger log.Logger, controlPlane nodes.Node, storage *config.Storage) (string, error) {
 	// start with fallback default, and then try to get the newer kind node
 	// storage manifest if present
 	manifest := defaultStorageManifest
@@ -88,12 +115,35 @@ func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
 		manifest = raw.String()
 	}
 
+	// newer manifests are templated with the storage options
+	templated := manifest
+	if strings.Contains(manifest, "would you kindly template this file") {
+		t, err := template.New("storage-manifest").Parse(manifest)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse storage manifest template")
+		}
+		var out bytes.Buffer
+		err = t.Execute(&out, &struct {
+			DefaultClass bool
+			LocalPath    string
+			Persistent   bool
+		}{
+			DefaultClass: !storage.NonDefaultStorageClass,
+			LocalPath:    storage.LocalPath,
+			Persistent:   storage.PersistentHostPath != "",
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to execute storage manifest template")
+		}
+		templated = out.String()
+	}
+
 	// apply the manifest
-	in := strings.NewReader(manifest)
+	in := strings.NewReader(templated)
 	cmd := controlPlane.Command(
 		"kubectl",
 		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
 	)
 	cmd.SetStdin(in)
-	return cmd.Run()
+	return manifest, cmd.Run()
 }
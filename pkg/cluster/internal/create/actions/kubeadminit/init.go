@@ -20,12 +20,16 @@ package kubeadminit
 import (
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/version"
+
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // kubeadmInitAction implements action for executing the kubadm init
@@ -39,9 +43,9 @@ func NewAction() actions.Action {
 }
 
 // Execute runs the action
-func (a *action) Execute(ctx *actions.ActionContext) error {
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
 	ctx.Status.Start("Starting control-plane 🕹️")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	allNodes, err := ctx.Nodes()
 	if err != nil {
@@ -56,10 +60,9 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
-	// run kubeadm
-	cmd := node.Command(
+	args := []string{
 		// init because this is the control plane node
-		"kubeadm", "init",
+		"init",
 		// preflight errors are expected, in particular for swap being enabled
 		// TODO(bentheelder): limit the set of acceptable errors
 		"--ignore-preflight-errors=all",
@@ -68,13 +71,49 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		"--skip-token-print",
 		// increase verbosity for debugging
 		"--v=6",
-	)
+	}
+
+	// kubeadm only supports skipping the kube-proxy addon from v1.13,
+	// for older versions we delete it after init instead
+	removeKubeProxy := false
+	if ctx.Config.Networking.KubeProxyMode == config.NoneProxyMode {
+		kubeVersion, err := nodeutils.KubeVersion(node)
+		if err != nil {
+			return errors.Wrap(err, "failed to get kubernetes version from node")
+		}
+		ver, err := version.ParseGeneric(kubeVersion)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse kubernetes version")
+		}
+		if ver.LessThan(version.MustParseSemantic("v1.13.0")) {
+			removeKubeProxy = true
+		} else {
+			args = append(args, "--skip-phases=addon/kube-proxy")
+		}
+	}
+
+	// undo any previous failed attempt before running kubeadm again
+	if _, err := kubeadm.PrepareNode(ctx.Logger, node); err != nil {
+		return err
+	}
+
+	// run kubeadm
+	cmd := node.Command("kubeadm", args...)
 	lines, err := exec.CombinedOutputLines(cmd)
 	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
 		return errors.Wrap(err, "failed to init node with kubeadm")
 	}
 
+	if removeKubeProxy {
+		if err := node.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"--namespace=kube-system", "delete", "daemonset", "kube-proxy",
+		).Run(); err != nil {
+			return errors.Wrap(err, "failed to remove kube-proxy")
+		}
+	}
+
 	// copy some files to the other control plane nodes
 	otherControlPlanes, err := nodeutils.SecondaryControlPlaneNodes(allNodes)
 	if err != nil {
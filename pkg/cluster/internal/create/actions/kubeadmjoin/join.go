@@ -29,6 +29,8 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
 )
 
 // Action implements action for creating the kubeadm join
@@ -47,13 +49,25 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	// nodes are removed from the cluster through the bootstrap control plane
+	// if a previous attempt at joining them failed
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
 	// join secondary control plane nodes if any
 	secondaryControlPlanes, err := nodeutils.SecondaryControlPlaneNodes(allNodes)
 	if err != nil {
 		return err
 	}
 	if len(secondaryControlPlanes) > 0 {
-		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes); err != nil {
+		// kubeadm init has created the cluster CA, so the load balancer can
+		// now verify the API servers before any nodes join through it
+		if err := loadbalancer.Reconcile(allNodes, ctx.Config); err != nil {
+			return err
+		}
+		if err := joinSecondaryControlPlanes(ctx, controlPlane, secondaryControlPlanes); err != nil {
 			return err
 		}
 	}
@@ -64,7 +78,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 	if len(workers) > 0 {
-		if err := joinWorkers(ctx, workers); err != nil {
+		if err := joinWorkers(ctx, controlPlane, workers); err != nil {
 			return err
 		}
 	}
@@ -74,16 +88,17 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 
 func joinSecondaryControlPlanes(
 	ctx *actions.ActionContext,
+	controlPlane nodes.Node,
 	secondaryControlPlanes []nodes.Node,
-) error {
+) (err error) {
 	ctx.Status.Start("Joining more control-plane nodes 🎮")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	// TODO(bentheelder): it's too bad we can't do this concurrently
 	// (this is not safe currently)
 	for _, node := range secondaryControlPlanes {
 		node := node // capture loop variable
-		if err := runKubeadmJoin(ctx.Logger, node); err != nil {
+		if err := runKubeadmJoin(ctx.Logger, controlPlane, node); err != nil {
 			return err
 		}
 	}
@@ -94,17 +109,18 @@ func joinSecondaryControlPlanes(
 
 func joinWorkers(
 	ctx *actions.ActionContext,
+	controlPlane nodes.Node,
 	workers []nodes.Node,
-) error {
+) (err error) {
 	ctx.Status.Start("Joining worker nodes 🚜")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
 	// create the workers concurrently
 	fns := []func() error{}
 	for _, node := range workers {
 		node := node // capture loop variable
 		fns = append(fns, func() error {
-			return runKubeadmJoin(ctx.Logger, node)
+			return runKubeadmJoin(ctx.Logger, controlPlane, node)
 		})
 	}
 	if err := errors.UntilErrorConcurrent(fns); err != nil {
@@ -115,8 +131,32 @@ func joinWorkers(
 	return nil
 }
 
+// joinedMarker is written to nodes after they have successfully joined,
+// so that resuming a failed cluster creation only retries the failed nodes
+const joinedMarker = "/kind/kubeadm-join-complete"
+
 // runKubeadmJoin executes kubadm join command
-func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
+func runKubeadmJoin(logger log.Logger, controlPlane, node nodes.Node) error {
+	// skip nodes that already joined in a previous attempt
+	if node.Command("test", "-f", joinedMarker).Run() == nil {
+		logger.V(2).Infof("skipping kubeadm join on %s, it has already joined", node.String())
+		return nil
+	}
+
+	// undo a previous failed attempt, which may also have registered the node
+	reset, err := kubeadm.PrepareNode(logger, node)
+	if err != nil {
+		return err
+	}
+	if reset {
+		if err := controlPlane.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"delete", "node", node.String(), "--ignore-not-found",
+		).Run(); err != nil {
+			return errors.Wrap(err, "failed to remove node from the cluster")
+		}
+	}
+
 	// run kubeadm join
 	// TODO(bentheelder): this should be using the config file
 	cmd := node.Command(
@@ -135,5 +175,8 @@ func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
 		return errors.Wrap(err, "failed to join node with kubeadm")
 	}
 
+	if err := nodeutils.WriteFile(node, joinedMarker, ""); err != nil {
+		return errors.Wrap(err, "failed to mark node as joined")
+	}
 	return nil
 }
@@ -18,14 +18,8 @@ limitations under the License.
 package loadbalancer
 
 import (
-	"fmt"
-
-	"sigs.k8s.io/kind/pkg/cluster/constants"
-	"sigs.k8s.io/kind/pkg/errors"
-
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
-	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
@@ -39,7 +33,7 @@ func NewAction() actions.Action {
 }
 
 // Execute runs the action
-func (a *Action) Execute(ctx *actions.ActionContext) error {
+func (a *Action) Execute(ctx *actions.ActionContext) (err error) {
 	allNodes, err := ctx.Nodes()
 	if err != nil {
 		return err
@@ -56,58 +50,14 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return nil
 	}
 
-	// obtain IP family
-	ipv6 := false
-	if ctx.Config.Networking.IPFamily == "ipv6" {
-		ipv6 = true
-	}
-
 	// otherwise notify the user
 	ctx.Status.Start("Configuring the external load balancer ⚖️")
-	defer ctx.Status.End(false)
+	defer func() { ctx.Status.EndWithError(err) }()
 
-	// collect info about the existing controlplane nodes
-	var backendServers = map[string]string{}
-	controlPlaneNodes, err := nodeutils.SelectNodesByRole(
-		allNodes,
-		constants.ControlPlaneNodeRoleValue,
-	)
-	if err != nil {
+	// configure the loadbalancer for the current control-plane nodes
+	if err := loadbalancer.Reconcile(allNodes, ctx.Config); err != nil {
 		return err
 	}
-	for _, n := range controlPlaneNodes {
-		controlPlaneIPv4, controlPlaneIPv6, err := n.IP()
-		if err != nil {
-			return errors.Wrapf(err, "failed to get IP for node %s", n.String())
-		}
-		if controlPlaneIPv4 != "" && !ipv6 {
-			backendServers[n.String()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, common.APIServerInternalPort)
-		}
-		if controlPlaneIPv6 != "" && ipv6 {
-			backendServers[n.String()] = fmt.Sprintf("[%s]:%d", controlPlaneIPv6, common.APIServerInternalPort)
-		}
-	}
-
-	// create loadbalancer config data
-	loadbalancerConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
-		ControlPlanePort: common.APIServerInternalPort,
-		BackendServers:   backendServers,
-		IPv6:             ipv6,
-	})
-	if err != nil {
-		return errors.Wrap(err, "failed to generate loadbalancer config data")
-	}
-
-	// create loadbalancer config on the node
-	if err := nodeutils.WriteFile(loadBalancerNode, loadbalancer.ConfigPath, loadbalancerConfig); err != nil {
-		// TODO: logging here
-		return errors.Wrap(err, "failed to copy loadbalancer config to node")
-	}
-
-	// reload the config. haproxy will reload on SIGHUP
-	if err := loadBalancerNode.Command("kill", "-s", "HUP", "1").Run(); err != nil {
-		return errors.Wrap(err, "failed to reload loadbalancer")
-	}
 
 	ctx.Status.End(true)
 	return nil
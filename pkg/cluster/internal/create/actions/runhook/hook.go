@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runhook implements the action to run a user supplied lifecycle
+// hook from the cluster config
+package runhook
+
+import (
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+type action struct {
+	phase string
+	hook  config.Hook
+}
+
+// NewAction returns a new action for running hook during phase
+func NewAction(phase string, hook config.Hook) actions.Action {
+	return &action{
+		phase: phase,
+		hook:  hook,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
+	name := Name(a.hook)
+	ctx.Status.Start("Running " + a.phase + " hook " + name + " 🎣")
+	defer func() { ctx.Status.EndWithError(err) }()
+
+	if a.hook.RunOnHost {
+		cmd := exec.Command(a.hook.Command[0], a.hook.Command[1:]...)
+		cmd.SetEnv(append(os.Environ(), "KIND_CLUSTER_NAME="+ctx.ClusterContext.Name())...)
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrapf(err, "%s hook %q failed on the host", a.phase, name)
+		}
+		ctx.Status.End(true)
+		return nil
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	targets, err := selectNodes(allNodes, a.hook.Roles)
+	if err != nil {
+		return err
+	}
+
+	// run the hook in all matching nodes concurrently
+	fns := []func() error{}
+	for _, node := range targets {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			cmd := node.Command(a.hook.Command[0], a.hook.Command[1:]...)
+			lines, err := exec.CombinedOutputLines(cmd)
+			ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+			if err != nil {
+				return errors.Wrapf(err, "%s hook %q failed on node %s", a.phase, name, node.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	ctx.Status.End(true)
+	return nil
+}
+
+// Name returns the name to report hook with
+func Name(hook config.Hook) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return strings.Join(hook.Command, " ")
+}
+
+// selectNodes returns the nodes in allNodes matching roles, or all
+// control-plane and worker nodes if roles is empty
+func selectNodes(allNodes []nodes.Node, roles []config.NodeRole) ([]nodes.Node, error) {
+	if len(roles) == 0 {
+		roles = []config.NodeRole{config.ControlPlaneRole, config.WorkerRole}
+	}
+	selected := []nodes.Node{}
+	for _, node := range allNodes {
+		nodeRole, err := node.Role()
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if nodeRole == string(role) {
+				selected = append(selected, node)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package writefiles implements the action to write the files from the
+// cluster config into the nodes
+package writefiles
+
+import (
+	"io/ioutil"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+const (
+	defaultPermissions = "0644"
+	defaultOwner       = "root:root"
+)
+
+type action struct{}
+
+// NewAction returns a new action for writing files into the nodes
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) (err error) {
+	ctx.Status.Start("Writing files 📁")
+	defer func() { ctx.Status.EndWithError(err) }()
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	nodeConfigs := ctx.NodeConfigs()
+
+	// read host files once up front
+	contents := map[string]string{}
+	readContent := func(f config.File) error {
+		if f.HostPath == "" {
+			return nil
+		}
+		if _, ok := contents[f.HostPath]; ok {
+			return nil
+		}
+		raw, err := ioutil.ReadFile(f.HostPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %q", f.HostPath)
+		}
+		contents[f.HostPath] = string(raw)
+		return nil
+	}
+
+	// write the files to all kubernetes nodes concurrently
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		role, err := node.Role()
+		if err != nil {
+			return err
+		}
+		if role != constants.ControlPlaneNodeRoleValue && role != constants.WorkerNodeRoleValue {
+			continue
+		}
+		files := append([]config.File{}, ctx.Config.Files...)
+		if nodeConfig, ok := nodeConfigs[node.String()]; ok {
+			files = append(files, nodeConfig.Files...)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		for _, f := range files {
+			if err := readContent(f); err != nil {
+				return err
+			}
+		}
+		fns = append(fns, func() error {
+			for _, f := range files {
+				if err := writeFile(node, f, contents); err != nil {
+					return errors.Wrapf(err, "failed to write %q to node %s", f.Path, node.String())
+				}
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// writeFile writes f to node, hostContents holds the content of any files
+// read from the host, keyed by host path
+func writeFile(node nodes.Node, f config.File, hostContents map[string]string) error {
+	content := f.Content
+	if f.HostPath != "" {
+		content = hostContents[f.HostPath]
+	}
+	if err := nodeutils.WriteFile(node, f.Path, content); err != nil {
+		return err
+	}
+	permissions := f.Permissions
+	if permissions == "" {
+		permissions = defaultPermissions
+	}
+	if err := node.Command("chmod", permissions, f.Path).Run(); err != nil {
+		return errors.Wrap(err, "failed to set permissions")
+	}
+	owner := f.Owner
+	if owner == "" {
+		owner = defaultOwner
+	}
+	if err := node.Command("chown", owner, f.Path).Run(); err != nil {
+		return errors.Wrap(err, "failed to set owner")
+	}
+	return nil
+}
@@ -33,14 +33,19 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/applymanifests"
 	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installservicelb"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/runhook"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/writefiles"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	lb "sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
 )
 
 const (
@@ -68,6 +73,53 @@ type ClusterOptions struct {
 	// Options to control output
 	DisplayUsage      bool
 	DisplaySalutation bool
+	// Resume skips node creation and all actions recorded as completed by a
+	// previous, failed attempt at creating the cluster with the same config,
+	// nodes are always retained when resuming
+	Resume bool
+	// Options to control the actions run after creating nodes
+	// ActionsBefore and ActionsAfter are keyed by built-in action name
+	ActionsBefore map[string][]NamedAction
+	ActionsAfter  map[string][]NamedAction
+	SkipActions   map[string]bool
+}
+
+// NamedAction is an actions.Action with a name, used to order actions and
+// to report progress
+type NamedAction struct {
+	Name   string
+	Action actions.Action
+}
+
+// These are the names of the built-in create actions, in the order they run
+const (
+	WriteFilesAction       = "writefiles"
+	LoadBalancerAction     = "loadbalancer"
+	ConfigAction           = "config"
+	KubeadmInitAction      = "kubeadminit"
+	InstallCNIAction       = "installcni"
+	InstallStorageAction   = "installstorage"
+	InstallServiceLBAction = "installservicelb"
+	KubeadmJoinAction      = "kubeadmjoin"
+	ApplyManifestsAction   = "applymanifests"
+	WaitForReadyAction     = "waitforready"
+)
+
+// BuiltInActionNames returns the names of all built-in create actions,
+// in the order they run
+func BuiltInActionNames() []string {
+	return []string{
+		WriteFilesAction,
+		LoadBalancerAction,
+		ConfigAction,
+		KubeadmInitAction,
+		InstallCNIAction,
+		InstallStorageAction,
+		InstallServiceLBAction,
+		KubeadmJoinAction,
+		ApplyManifestsAction,
+		WaitForReadyAction,
+	}
 }
 
 // Cluster creates a cluster
@@ -94,51 +146,74 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 		return err
 	}
 
+	// determine the actions to run
+	actionsToRun, err := actionsForOptions(opts)
+	if err != nil {
+		return err
+	}
+
 	// setup a status object to show progress to the user
 	status := cli.StatusForLogger(logger)
 
-	// Create node containers implementing defined config Nodes
-	if err := ctx.Provider().Provision(status, ctx.Name(), opts.Config); err != nil {
+	// when resuming, the nodes already exist and we must never delete them
+	retain := opts.Retain || opts.Resume
+
+	if opts.Resume {
+		// the nodes were created by a previous attempt
+		n, err := ctx.ListNodes()
+		if err != nil {
+			return err
+		}
+		if len(n) == 0 {
+			return errors.Errorf("no nodes found for cluster %q, cannot resume creating it", ctx.Name())
+		}
+		// the nodes may have been restarted with new IPs since, so point the
+		// load balancer at the current control-plane nodes
+		if err := lb.Reconcile(n, opts.Config); err != nil {
+			return err
+		}
+	} else if err := ctx.Provider().Provision(status, ctx.Name(), opts.Config); err != nil {
+		// Create node containers implementing defined config Nodes
 		// In case of errors nodes are deleted (except if retain is explicitly set)
 		logger.Errorf("%v", err)
-		if !opts.Retain {
+		if !retain {
 			_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
 		}
 		return err
 	}
 
-	// TODO(bentheelder): make this controllable from the command line?
-	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
-	}
-	if !opts.StopBeforeSettingUpKubernetes {
-		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(), // run kubeadm init
-		)
-		// this step might be skipped, but is next after init
-		if !opts.Config.Networking.DisableDefaultCNI {
-			actionsToRun = append(actionsToRun,
-				installcni.NewAction(), // install CNI
-			)
+	// run all actions, recording which have completed
+	actionsContext := actions.NewActionContext(logger, opts.Config, ctx, status)
+	state, err := loadCreateState(actionsContext)
+	if err != nil {
+		if !retain {
+			_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
 		}
-		// add remaining steps
-		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
-		)
+		return err
 	}
-
-	// run all actions
-	actionsContext := actions.NewActionContext(logger, opts.Config, ctx, status)
-	for _, action := range actionsToRun {
-		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
+	// deleteOnError controls deleting the cluster when an action fails,
+	// unless retained
+	runActions := func(actionsToRun []NamedAction, deleteOnError bool) error {
+		for _, action := range actionsToRun {
+			if opts.Resume && state.Completed(action.Name) {
+				logger.V(0).Infof(" • Skipping completed step %q", action.Name)
+				continue
+			}
+			err := runAction(logger, action, actionsContext)
+			if err == nil {
+				err = state.MarkCompleted(action.Name)
+			}
+			if err != nil {
+				if deleteOnError && !retain {
+					_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
+				}
+				return err
 			}
-			return err
 		}
+		return nil
+	}
+	if err := runActions(actionsToRun, true); err != nil {
+		return err
 	}
 
 	// skip the rest if we're not setting up kubernetes
@@ -150,6 +225,13 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 		return err
 	}
 
+	// run any post create hooks, now that the kubeconfig is available
+	// the cluster is fully created at this point, so it is kept if a hook
+	// fails, and the hook can be retried with --resume
+	if err := runActions(hookActions("postCreate", opts.Config.Hooks.PostCreate), false); err != nil {
+		return errors.Wrap(err, "cluster was created but a postCreate hook failed")
+	}
+
 	// optionally display usage
 	if opts.DisplayUsage {
 		logUsage(logger, ctx, opts.KubeconfigPath)
@@ -162,6 +244,156 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 	return nil
 }
 
+// actionsForOptions returns the ordered actions to run for opts, including
+// any extra actions and excluding any skipped actions
+func actionsForOptions(opts *ClusterOptions) ([]NamedAction, error) {
+	builtIn := []NamedAction{
+		{WriteFilesAction, writefiles.NewAction()},     // write files into the nodes
+		{LoadBalancerAction, loadbalancer.NewAction()}, // setup external loadbalancer
+		{ConfigAction, configaction.NewAction()},       // setup kubeadm config
+	}
+	if !opts.StopBeforeSettingUpKubernetes {
+		builtIn = append(builtIn,
+			NamedAction{KubeadmInitAction, kubeadminit.NewAction()},                    // run kubeadm init
+			NamedAction{InstallCNIAction, installcni.NewAction()},                      // install CNI
+			NamedAction{InstallStorageAction, installstorage.NewAction()},              // install StorageClass
+			NamedAction{InstallServiceLBAction, installservicelb.NewAction()},          // install LoadBalancer controller
+			NamedAction{KubeadmJoinAction, kubeadmjoin.NewAction()},                    // run kubeadm join
+			NamedAction{ApplyManifestsAction, applymanifests.NewAction()},              // apply user manifests
+			NamedAction{WaitForReadyAction, waitforready.NewAction(opts.WaitForReady)}, // wait for cluster readiness
+		)
+	}
+
+	// validate that extra / skipped actions reference known actions
+	known := map[string]bool{}
+	for _, name := range BuiltInActionNames() {
+		known[name] = true
+	}
+	for _, names := range []map[string][]NamedAction{opts.ActionsBefore, opts.ActionsAfter} {
+		for name := range names {
+			if !known[name] {
+				return nil, errors.Errorf("cannot add actions relative to unknown action %q", name)
+			}
+		}
+	}
+	for name := range opts.SkipActions {
+		if !known[name] {
+			return nil, errors.Errorf("cannot skip unknown action %q", name)
+		}
+	}
+
+	// the default CNI might be disabled in the config
+	// and there may not be any files to write or manifests to apply
+	skip := map[string]bool{
+		WriteFilesAction:       !hasFiles(opts.Config),
+		InstallCNIAction:       opts.Config.Networking.DisableDefaultCNI,
+		InstallStorageAction:   opts.Config.Storage.DisableDefaultStorageClass,
+		InstallServiceLBAction: !opts.Config.Networking.EnableLoadBalancer,
+		ApplyManifestsAction:   len(opts.Config.Manifests) == 0,
+	}
+	for name := range opts.SkipActions {
+		skip[name] = true
+	}
+
+	// NOTE: actions added relative to a skipped action still run, but
+	// actions added relative to actions that are not part of this create at
+	// all (see StopBeforeSettingUpKubernetes) do not
+	// config hooks run closest to the built-in action they are relative to
+	hooks := opts.Config.Hooks
+	hooksBefore := map[string][]NamedAction{
+		KubeadmInitAction: hookActions("preKubeadmInit", hooks.PreKubeadmInit),
+	}
+	hooksAfter := map[string][]NamedAction{
+		WriteFilesAction:  hookActions("postProvision", hooks.PostProvision),
+		KubeadmJoinAction: hookActions("postJoin", hooks.PostJoin),
+	}
+
+	// completed actions are recorded by name for --resume, so extra actions
+	// must not reuse the name of a built-in action, a hook or each other
+	names := map[string]bool{}
+	for _, name := range BuiltInActionNames() {
+		names[name] = true
+	}
+	for _, hooks := range [][]NamedAction{
+		hooksBefore[KubeadmInitAction],
+		hooksAfter[WriteFilesAction],
+		hooksAfter[KubeadmJoinAction],
+		hookActions("postCreate", hooks.PostCreate),
+	} {
+		for _, hook := range hooks {
+			names[hook.Name] = true
+		}
+	}
+	for _, extra := range []map[string][]NamedAction{opts.ActionsBefore, opts.ActionsAfter} {
+		for _, actions := range extra {
+			for _, action := range actions {
+				if names[action.Name] {
+					return nil, errors.Errorf("duplicate action name %q, action names must be unique and cannot be the name of a built-in action or hook", action.Name)
+				}
+				names[action.Name] = true
+			}
+		}
+	}
+
+	actionsToRun := []NamedAction{}
+	for _, action := range builtIn {
+		actionsToRun = append(actionsToRun, opts.ActionsBefore[action.Name]...)
+		actionsToRun = append(actionsToRun, hooksBefore[action.Name]...)
+		if !skip[action.Name] {
+			actionsToRun = append(actionsToRun, action)
+		}
+		actionsToRun = append(actionsToRun, hooksAfter[action.Name]...)
+		actionsToRun = append(actionsToRun, opts.ActionsAfter[action.Name]...)
+	}
+	return actionsToRun, nil
+}
+
+// hasFiles returns true if there are any files to write in cfg
+func hasFiles(cfg *config.Cluster) bool {
+	if len(cfg.Files) > 0 {
+		return true
+	}
+	for _, n := range cfg.Nodes {
+		if len(n.Files) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hookActions returns actions running the config hooks for phase
+func hookActions(phase string, hooks []config.Hook) []NamedAction {
+	hookActions := []NamedAction{}
+	for i, hook := range hooks {
+		hookActions = append(hookActions, NamedAction{
+			Name:   fmt.Sprintf("%s-hook-%d", phase, i),
+			Action: runhook.NewAction(phase, hook),
+		})
+	}
+	return hookActions
+}
+
+// runAction executes action, reporting the action boundaries to logger
+// if it is a log.EventSink
+func runAction(logger log.Logger, action NamedAction, ctx *actions.ActionContext) error {
+	log.SendEvent(logger, log.Event{
+		Type: log.ActionStartEvent,
+		Step: action.Name,
+	})
+	err := action.Action.Execute(ctx)
+	success := err == nil
+	end := log.Event{
+		Type:    log.ActionEndEvent,
+		Step:    action.Name,
+		Success: &success,
+	}
+	if err != nil {
+		end.Error = err.Error()
+	}
+	log.SendEvent(logger, end)
+	return err
+}
+
 func logUsage(logger log.Logger, ctx *context.Context, explicitKubeconfigPath string) {
 	// construct a sample command for interacting with the cluster
 	kctx := kubeconfig.ContextForCluster(ctx.Name())
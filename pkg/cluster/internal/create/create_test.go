@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+type nopAction struct{}
+
+func (n nopAction) Execute(ctx *actions.ActionContext) error { return nil }
+
+func TestActionsForOptions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name          string
+		Options       ClusterOptions
+		ExpectActions []string
+		ExpectError   bool
+	}{
+		{
+			Name:          "defaults",
+			Options:       ClusterOptions{},
+			ExpectActions: []string{"loadbalancer", "config", "kubeadminit", "installcni", "installstorage", "kubeadmjoin", "waitforready"},
+		},
+		{
+			Name: "with files, manifests and load balancer",
+			Options: ClusterOptions{
+				Config: &config.Cluster{
+					Nodes:      []config.Node{{Files: []config.File{{Path: "/etc/motd", Content: "hello"}}}},
+					Manifests:  []config.Manifest{{Path: "crds.yaml"}},
+					Networking: config.Networking{EnableLoadBalancer: true},
+				},
+			},
+			ExpectActions: BuiltInActionNames(),
+		},
+		{
+			Name: "default CNI disabled",
+			Options: ClusterOptions{
+				Config: &config.Cluster{
+					Networking: config.Networking{DisableDefaultCNI: true},
+				},
+			},
+			ExpectActions: []string{"loadbalancer", "config", "kubeadminit", "installstorage", "kubeadmjoin", "waitforready"},
+		},
+		{
+			Name: "default StorageClass disabled",
+			Options: ClusterOptions{
+				Config: &config.Cluster{
+					Storage: config.Storage{DisableDefaultStorageClass: true},
+				},
+			},
+			ExpectActions: []string{"loadbalancer", "config", "kubeadminit", "installcni", "kubeadmjoin", "waitforready"},
+		},
+		{
+			Name: "with hooks",
+			Options: ClusterOptions{
+				Config: &config.Cluster{
+					Hooks: config.Hooks{
+						PostProvision:  []config.Hook{{Command: []string{"true"}}},
+						PreKubeadmInit: []config.Hook{{Command: []string{"true"}}, {Command: []string{"true"}}},
+						PostJoin:       []config.Hook{{Command: []string{"true"}}},
+						PostCreate:     []config.Hook{{Command: []string{"true"}}},
+					},
+				},
+				ActionsBefore: map[string][]NamedAction{
+					"kubeadminit": {{"seed-secrets", nopAction{}}},
+				},
+			},
+			ExpectActions: []string{"postProvision-hook-0", "loadbalancer", "config", "seed-secrets", "preKubeadmInit-hook-0", "preKubeadmInit-hook-1", "kubeadminit", "installcni", "installstorage", "kubeadmjoin", "postJoin-hook-0", "waitforready"},
+		},
+		{
+			Name: "extra and skipped actions",
+			Options: ClusterOptions{
+				ActionsBefore: map[string][]NamedAction{
+					"kubeadminit": {{"seed-secrets", nopAction{}}},
+				},
+				ActionsAfter: map[string][]NamedAction{
+					"installstorage": {{"install-crds", nopAction{}}, {"install-csi", nopAction{}}},
+				},
+				SkipActions: map[string]bool{"installstorage": true},
+			},
+			ExpectActions: []string{"loadbalancer", "config", "seed-secrets", "kubeadminit", "installcni", "install-crds", "install-csi", "kubeadmjoin", "waitforready"},
+		},
+		{
+			Name: "stop before setting up kubernetes",
+			Options: ClusterOptions{
+				StopBeforeSettingUpKubernetes: true,
+				ActionsAfter: map[string][]NamedAction{
+					"config":      {{"before-kubernetes", nopAction{}}},
+					"kubeadminit": {{"after-kubernetes", nopAction{}}},
+				},
+			},
+			ExpectActions: []string{"loadbalancer", "config", "before-kubernetes"},
+		},
+		{
+			Name: "unknown action anchor",
+			Options: ClusterOptions{
+				ActionsAfter: map[string][]NamedAction{
+					"kubeadm-init": {{"after-kubernetes", nopAction{}}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "extra action named like a built-in action",
+			Options: ClusterOptions{
+				ActionsBefore: map[string][]NamedAction{
+					"kubeadminit": {{"kubeadminit", nopAction{}}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "extra action named like a hook",
+			Options: ClusterOptions{
+				Config: &config.Cluster{
+					Hooks: config.Hooks{
+						PostCreate: []config.Hook{{Command: []string{"true"}}},
+					},
+				},
+				ActionsAfter: map[string][]NamedAction{
+					"waitforready": {{"postCreate-hook-0", nopAction{}}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "duplicate extra actions",
+			Options: ClusterOptions{
+				ActionsBefore: map[string][]NamedAction{
+					"kubeadminit": {{"seed-secrets", nopAction{}}},
+				},
+				ActionsAfter: map[string][]NamedAction{
+					"kubeadmjoin": {{"seed-secrets", nopAction{}}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "unknown skipped action",
+			Options: ClusterOptions{
+				SkipActions: map[string]bool{"cni": true},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if tc.Options.Config == nil {
+				tc.Options.Config = &config.Cluster{}
+			}
+			result, err := actionsForOptions(&tc.Options)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			names := []string{}
+			for _, a := range result {
+				names = append(names, a.Name)
+			}
+			assert.DeepEqual(t, tc.ExpectActions, names)
+		})
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	t.Parallel()
+	newConfig := func() *config.Cluster {
+		cfg := &config.Cluster{
+			Nodes: []config.Node{{Role: config.ControlPlaneRole}, {Role: config.WorkerRole}},
+			Files: []config.File{{Path: "/etc/motd", Content: "hello"}},
+		}
+		config.SetDefaultsCluster(cfg)
+		return cfg
+	}
+	hash, err := configHash(newConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	same, err := configHash(newConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.StringEqual(t, hash, same)
+	changed := newConfig()
+	changed.Files[0].Content = "goodbye"
+	different, err := configHash(changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if different == hash {
+		t.Errorf("expected a different hash for a changed config")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// stateFile is where the create state is recorded on the bootstrap control
+// plane node: the hash of the cluster config on the first line, followed by
+// the names of the completed create actions, one per line
+const stateFile = "/kind/create-state"
+
+// createState tracks which create actions have completed, so that a failed
+// cluster creation can be resumed
+type createState struct {
+	node       nodes.Node
+	configHash string
+	completed  []string
+}
+
+// configHash returns a hash identifying the effective cluster config cfg
+func configHash(cfg *config.Cluster) (string, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash cluster config")
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// loadCreateState reads the create state from the bootstrap control plane,
+// a missing state file is treated as no actions having completed
+//
+// The state can only be loaded for the config it was recorded with, as the
+// completed actions may not match a different config
+func loadCreateState(ctx *actions.ActionContext) (*createState, error) {
+	hash, err := configHash(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return nil, err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	s := &createState{node: node, configHash: hash}
+	var buff bytes.Buffer
+	if err := node.Command("cat", stateFile).SetStdout(&buff).Run(); err != nil {
+		// the file does not exist until the state is first saved
+		if node.Command("test", "-f", stateFile).Run() != nil {
+			return s, s.save()
+		}
+		return nil, errors.Wrap(err, "failed to read create state")
+	}
+	lines := strings.Split(buff.String(), "\n")
+	if lines[0] != hash {
+		return nil, errors.New("the cluster config differs from the config the cluster was created with, cannot resume creating it")
+	}
+	for _, line := range lines[1:] {
+		if line != "" {
+			s.completed = append(s.completed, line)
+		}
+	}
+	return s, nil
+}
+
+// Completed returns true if the action named name has completed
+func (s *createState) Completed(name string) bool {
+	for _, c := range s.completed {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkCompleted records that the action named name has completed
+func (s *createState) MarkCompleted(name string) error {
+	if s.Completed(name) {
+		return nil
+	}
+	s.completed = append(s.completed, name)
+	return s.save()
+}
+
+// save writes the state to the state file
+func (s *createState) save() error {
+	content := strings.Join(append([]string{s.configHash}, s.completed...), "\n") + "\n"
+	if err := nodeutils.WriteFile(s.node, stateFile, content); err != nil {
+		return errors.Wrap(err, "failed to record create state")
+	}
+	return nil
+}
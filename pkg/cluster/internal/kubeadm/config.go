@@ -18,6 +18,8 @@ package kubeadm
 
 import (
 	"bytes"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -49,6 +51,20 @@ type ConfigData struct {
 	ServiceSubnet string
 	// IPv4 values take precedence over IPv6 by default, if true set IPv6 default values
 	IPv6 bool
+	// DualStack is true for dual stack IPv4 / IPv6 clusters, NodeAddress
+	// should then be the IPv4 address and NodeAddressIPv6 the IPv6 address
+	DualStack bool
+	// The IPv6 address of the node, only used for dual stack clusters
+	NodeAddressIPv6 string
+	// FeatureGates are set on every Kubernetes component
+	FeatureGates map[string]bool
+	// RuntimeConfig is set on the API server
+	RuntimeConfig map[string]string
+	// KubeProxyMode is the kube-proxy mode, if this is "none" kube-proxy
+	// will not be deployed and the mode is not set
+	KubeProxyMode string
+	// NodeLabels are set on the node by the kubelet
+	NodeLabels map[string]string
 	// DerivedConfigData is populated by Derive()
 	// These auto-generated fields are available to Config templates,
 	// but not meant to be set by hand
@@ -60,13 +76,56 @@ type ConfigData struct {
 type DerivedConfigData struct {
 	// DockerStableTag is automatically derived from KubernetesVersion
 	DockerStableTag string
+	// FeatureGatesString is FeatureGates in --feature-gates flag format
+	FeatureGatesString string
+	// RuntimeConfigString is RuntimeConfig in --runtime-config flag format
+	RuntimeConfigString string
+	// NodeIPs is the kubelet --node-ip, this is NodeAddress unless set
+	NodeIPs string
+	// NodeLabelsString is NodeLabels in --node-labels flag format
+	NodeLabelsString string
+	// IPv6DualStackFeatureGate is true if kubeadm's IPv6DualStack feature
+	// gate must be enabled, set by Config for dual stack clusters
+	IPv6DualStackFeatureGate bool
 }
 
-// Derive automatically derives DockerStableTag if not specified
+// Derive automatically derives DockerStableTag, FeatureGatesString,
+// RuntimeConfigString, NodeIPs and NodeLabelsString if not specified
 func (c *ConfigData) Derive() {
 	if c.DockerStableTag == "" {
 		c.DockerStableTag = strings.Replace(c.KubernetesVersion, "+", "_", -1)
 	}
+	if c.FeatureGatesString == "" {
+		gates := make(map[string]string, len(c.FeatureGates))
+		for gate, enabled := range c.FeatureGates {
+			gates[gate] = strconv.FormatBool(enabled)
+		}
+		c.FeatureGatesString = flagMapString(gates)
+	}
+	if c.RuntimeConfigString == "" {
+		c.RuntimeConfigString = flagMapString(c.RuntimeConfig)
+	}
+	if c.NodeIPs == "" {
+		c.NodeIPs = c.NodeAddress
+	}
+	if c.NodeLabelsString == "" {
+		c.NodeLabelsString = flagMapString(c.NodeLabels)
+	}
+}
+
+// flagMapString formats m as a comma separated list of key=value pairs,
+// sorted by key, as expected by Kubernetes component map flags
+func flagMapString(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+m[k])
+	}
+	return strings.Join(pairs, ",")
 }
 
 // See docs for these APIs at:
@@ -109,6 +168,15 @@ apiServerExtraVolumes:
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServerCertSANs: [localhost, "{{.APIServerAddress}}"]
+{{ if or .FeatureGates .RuntimeConfig -}}
+apiServerExtraArgs:
+  {{ if .FeatureGates -}}
+  feature-gates: "{{ .FeatureGatesString }}"
+  {{- end }}
+  {{ if .RuntimeConfig -}}
+  runtime-config: "{{ .RuntimeConfigString }}"
+  {{- end }}
+{{- end }}
 kubeletConfiguration:
   baseConfig:
     # configure ipv6 addresses in IPv6 mode
@@ -125,13 +193,42 @@ kubeletConfiguration:
       nodefs.available: "0%"
       nodefs.inodesFree: "0%"
       imagefs.available: "0%"
+    {{ if .FeatureGates -}}
+    featureGates:
+    {{- range $gate, $enabled := .FeatureGates }}
+      "{{ $gate }}": {{ $enabled }}
+    {{- end }}
+    {{- end }}
 controllerManagerExtraArgs:
   enable-hostpath-provisioner: "true"
+  {{ if .FeatureGates -}}
+  feature-gates: "{{ .FeatureGatesString }}"
+  {{- end }}
+{{ if .FeatureGates -}}
+schedulerExtraArgs:
+  feature-gates: "{{ .FeatureGatesString }}"
+{{- end }}
+{{ if or .FeatureGates (and .KubeProxyMode (ne .KubeProxyMode "none")) -}}
+kubeProxy:
+  config:
+    {{ if and .KubeProxyMode (ne .KubeProxyMode "none") -}}
+    mode: "{{ .KubeProxyMode }}"
+    {{- end }}
+    {{ if .FeatureGates -}}
+    featureGates:
+    {{- range $gate, $enabled := .FeatureGates }}
+      "{{ $gate }}": {{ $enabled }}
+    {{- end }}
+    {{- end }}
+{{- end }}
 nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
 {{else}}# config for this worker node
@@ -147,7 +244,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 {{end}}
 `
 
@@ -175,8 +275,24 @@ apiServerExtraVolumes:
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServerCertSANs: [localhost, "{{.APIServerAddress}}"]
+{{ if or .FeatureGates .RuntimeConfig -}}
+apiServerExtraArgs:
+  {{ if .FeatureGates -}}
+  feature-gates: "{{ .FeatureGatesString }}"
+  {{- end }}
+  {{ if .RuntimeConfig -}}
+  runtime-config: "{{ .RuntimeConfigString }}"
+  {{- end }}
+{{- end }}
 controllerManagerExtraArgs:
   enable-hostpath-provisioner: "true"
+  {{ if .FeatureGates -}}
+  feature-gates: "{{ .FeatureGatesString }}"
+  {{- end }}
+{{ if .FeatureGates -}}
+schedulerExtraArgs:
+  feature-gates: "{{ .FeatureGatesString }}"
+{{- end }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
 ---
@@ -196,7 +312,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1alpha3
@@ -216,7 +335,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 ---
 apiVersion: kubelet.config.k8s.io/v1beta1
 kind: KubeletConfiguration
@@ -235,12 +357,27 @@ evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
 kind: KubeProxyConfiguration
 metadata:
   name: config
+{{ if and .KubeProxyMode (ne .KubeProxyMode "none") -}}
+mode: "{{ .KubeProxyMode }}"
+{{- end }}
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 `
 
 // ConfigTemplateBetaV1 is the kubadm config template for API version v1beta1
@@ -257,6 +394,15 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
   certSANs: [localhost, "{{.APIServerAddress}}"]
+  {{ if or .FeatureGates .RuntimeConfig -}}
+  extraArgs:
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
+    {{ if .RuntimeConfig -}}
+    runtime-config: "{{ .RuntimeConfigString }}"
+    {{- end }}
+  {{- end }}
 controllerManager:
   extraArgs:
     enable-hostpath-provisioner: "true"
@@ -264,6 +410,9 @@ controllerManager:
     {{ if .IPv6 -}}
     bind-address: "::"
     {{- end }}
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
 scheduler:
   extraArgs:
     # configure ipv6 default addresses for IPv6 clusters
@@ -271,6 +420,9 @@ scheduler:
     address: "::"
     bind-address: "::1"
     {{- end }}
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
   serviceSubnet: "{{ .ServiceSubnet }}"
@@ -291,7 +443,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1beta1
@@ -308,7 +463,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
@@ -332,12 +490,27 @@ evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
 kind: KubeProxyConfiguration
 metadata:
   name: config
+{{ if and .KubeProxyMode (ne .KubeProxyMode "none") -}}
+mode: "{{ .KubeProxyMode }}"
+{{- end }}
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 `
 
 // ConfigTemplateBetaV2 is the kubadm config template for API version v1beta2
@@ -347,6 +520,10 @@ kind: ClusterConfiguration
 metadata:
   name: config
 kubernetesVersion: {{.KubernetesVersion}}
+{{ if .IPv6DualStackFeatureGate -}}
+featureGates:
+  IPv6DualStack: true
+{{ end -}}
 clusterName: "{{.ClusterName}}"
 controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # on docker for mac we have to expose the api server via port forward,
@@ -354,6 +531,15 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
   certSANs: [localhost, "{{.APIServerAddress}}"]
+  {{ if or .FeatureGates .RuntimeConfig -}}
+  extraArgs:
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
+    {{ if .RuntimeConfig -}}
+    runtime-config: "{{ .RuntimeConfigString }}"
+    {{- end }}
+  {{- end }}
 controllerManager:
   extraArgs:
     enable-hostpath-provisioner: "true"
@@ -361,6 +547,9 @@ controllerManager:
     {{ if .IPv6 -}}
     bind-address: "::"
     {{- end }}
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
 scheduler:
   extraArgs:
     # configure ipv6 default addresses for IPv6 clusters
@@ -368,6 +557,9 @@ scheduler:
     address: "::"
     bind-address: "::1"
     {{- end }}
+    {{ if .FeatureGates -}}
+    feature-gates: "{{ .FeatureGatesString }}"
+    {{- end }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
   serviceSubnet: "{{ .ServiceSubnet }}"
@@ -388,7 +580,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1beta2
@@ -405,7 +600,10 @@ nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
   kubeletExtraArgs:
     fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+    node-ip: "{{ .NodeIPs }}"
+{{- if .NodeLabelsString }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{- end }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
@@ -429,12 +627,27 @@ evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
 kind: KubeProxyConfiguration
 metadata:
   name: config
+{{ if and .KubeProxyMode (ne .KubeProxyMode "none") -}}
+mode: "{{ .KubeProxyMode }}"
+{{- end }}
+{{ if .FeatureGates -}}
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  "{{ $gate }}": {{ $enabled }}
+{{- end }}
+{{- end }}
 `
 
 // Config returns a kubeadm config generated from config data, in particular
@@ -455,6 +668,12 @@ func Config(data ConfigData) (config string, err error) {
 		templateSource = ConfigTemplateBetaV1
 	}
 
+	if data.DualStack {
+		if err := configureDualStack(&data, ver); err != nil {
+			return "", err
+		}
+	}
+
 	t, err := template.New("kubeadm-config").Parse(templateSource)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse config template")
@@ -471,3 +690,27 @@ func Config(data ConfigData) (config string, err error) {
 	}
 	return buff.String(), nil
 }
+
+// configureDualStack sets the version dependent config data for dual stack
+func configureDualStack(data *ConfigData, ver *version.Version) error {
+	// before v1.20 the kubelet only reports one address per node, so pod
+	// routes between nodes cannot be programmed for the other family
+	if ver.LessThan(version.MustParseSemantic("v1.20.0")) {
+		return errors.Errorf("dual stack clusters require Kubernetes v1.20.0 or newer, got %s", data.KubernetesVersion)
+	}
+	// before v1.21 dual stack was off by default, behind the IPv6DualStack
+	// feature gate, on kubeadm and every component
+	if ver.LessThan(version.MustParseSemantic("v1.21.0")) {
+		data.IPv6DualStackFeatureGate = true
+		gates := map[string]bool{"IPv6DualStack": true}
+		for gate, enabled := range data.FeatureGates {
+			gates[gate] = enabled
+		}
+		data.FeatureGates = gates
+	}
+	// the kubelet accepts an address for each family
+	if data.NodeIPs == "" && data.NodeAddressIPv6 != "" {
+		data.NodeIPs = data.NodeAddress + "," + data.NodeAddressIPv6
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestConfigFeatureGatesAndRuntimeConfig(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+	}{
+		{Name: "v1alpha2", KubernetesVersion: "v1.11.10"},
+		{Name: "v1alpha3", KubernetesVersion: "v1.12.10"},
+		{Name: "v1beta1", KubernetesVersion: "v1.14.10"},
+		{Name: "v1beta2", KubernetesVersion: "v1.17.0"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			// without gates the config should still be valid yaml
+			plain, err := Config(ConfigData{KubernetesVersion: tc.KubernetesVersion, ControlPlane: true})
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, false, strings.Contains(plain, "feature-gates"))
+			parseDocuments(t, plain)
+
+			out, err := Config(ConfigData{
+				KubernetesVersion: tc.KubernetesVersion,
+				ControlPlane:      true,
+				FeatureGates:      map[string]bool{"Foo": true, "Bar": false},
+				RuntimeConfig:     map[string]string{"api/alpha": "true"},
+			})
+			assert.ExpectError(t, false, err)
+			// apiserver, controller-manager and scheduler
+			assert.DeepEqual(t, 3, strings.Count(out, `feature-gates: "Bar=false,Foo=true"`))
+			assert.DeepEqual(t, 1, strings.Count(out, `runtime-config: "api/alpha=true"`))
+			expectedGates := map[string]interface{}{"Foo": true, "Bar": false}
+			for _, doc := range parseDocuments(t, out) {
+				switch doc["kind"] {
+				case "KubeletConfiguration", "KubeProxyConfiguration":
+					assert.DeepEqual(t, expectedGates, doc["featureGates"])
+				case "MasterConfiguration":
+					kubelet := doc["kubeletConfiguration"].(map[string]interface{})["baseConfig"].(map[string]interface{})
+					assert.DeepEqual(t, expectedGates, kubelet["featureGates"])
+					proxy := doc["kubeProxy"].(map[string]interface{})["config"].(map[string]interface{})
+					assert.DeepEqual(t, expectedGates, proxy["featureGates"])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigKubeProxyMode(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+		KubeProxyMode     string
+		ExpectedMode      interface{}
+	}{
+		{Name: "v1alpha2 ipvs", KubernetesVersion: "v1.11.10", KubeProxyMode: "ipvs", ExpectedMode: "ipvs"},
+		{Name: "v1alpha2 none", KubernetesVersion: "v1.11.10", KubeProxyMode: "none"},
+		{Name: "v1beta2 ipvs", KubernetesVersion: "v1.17.0", KubeProxyMode: "ipvs", ExpectedMode: "ipvs"},
+		{Name: "v1beta2 iptables", KubernetesVersion: "v1.17.0", KubeProxyMode: "iptables", ExpectedMode: "iptables"},
+		{Name: "v1beta2 none", KubernetesVersion: "v1.17.0", KubeProxyMode: "none"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion: tc.KubernetesVersion,
+				ControlPlane:      true,
+				KubeProxyMode:     tc.KubeProxyMode,
+			})
+			assert.ExpectError(t, false, err)
+			var mode interface{}
+			for _, doc := range parseDocuments(t, out) {
+				switch doc["kind"] {
+				case "KubeProxyConfiguration":
+					mode = doc["mode"]
+				case "MasterConfiguration":
+					if proxy, ok := doc["kubeProxy"].(map[string]interface{}); ok {
+						mode = proxy["config"].(map[string]interface{})["mode"]
+					}
+				}
+			}
+			assert.DeepEqual(t, tc.ExpectedMode, mode)
+		})
+	}
+}
+
+func TestConfigDualStack(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+		ExpectError       bool
+		ExpectedGate      interface{}
+		ExpectedNodeIP    string
+	}{
+		{Name: "too old", KubernetesVersion: "v1.15.3", ExpectError: true},
+		{Name: "single kubelet node ip", KubernetesVersion: "v1.19.4", ExpectError: true},
+		{Name: "feature gated", KubernetesVersion: "v1.20.0", ExpectedGate: true, ExpectedNodeIP: "172.17.0.2,fc00:f853:ccd:e793::2"},
+		{Name: "enabled by default", KubernetesVersion: "v1.21.1", ExpectedNodeIP: "172.17.0.2,fc00:f853:ccd:e793::2"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion: tc.KubernetesVersion,
+				ControlPlane:      true,
+				DualStack:         true,
+				NodeAddress:       "172.17.0.2",
+				NodeAddressIPv6:   "fc00:f853:ccd:e793::2",
+				PodSubnet:         "10.244.0.0/16,fd00:10:244::/56",
+			})
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			for _, doc := range parseDocuments(t, out) {
+				switch doc["kind"] {
+				case "ClusterConfiguration":
+					var gate interface{}
+					if gates, ok := doc["featureGates"].(map[string]interface{}); ok {
+						gate = gates["IPv6DualStack"]
+					}
+					assert.DeepEqual(t, tc.ExpectedGate, gate)
+					networking := doc["networking"].(map[string]interface{})
+					assert.DeepEqual(t, "10.244.0.0/16,fd00:10:244::/56", networking["podSubnet"])
+				case "KubeletConfiguration":
+					var gate interface{}
+					if gates, ok := doc["featureGates"].(map[string]interface{}); ok {
+						gate = gates["IPv6DualStack"]
+					}
+					assert.DeepEqual(t, tc.ExpectedGate, gate)
+				case "InitConfiguration":
+					kubeletArgs := doc["nodeRegistration"].(map[string]interface{})["kubeletExtraArgs"].(map[string]interface{})
+					assert.DeepEqual(t, tc.ExpectedNodeIP, kubeletArgs["node-ip"])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigNodeLabels(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+		NodeLabels        map[string]string
+		ExpectedLabels    interface{}
+	}{
+		{Name: "v1alpha3 no labels", KubernetesVersion: "v1.12.10"},
+		{Name: "v1alpha3 labels", KubernetesVersion: "v1.12.10", NodeLabels: map[string]string{"ingress-ready": "true"}, ExpectedLabels: "ingress-ready=true"},
+		{Name: "v1beta2 no labels", KubernetesVersion: "v1.17.0"},
+		{Name: "v1beta2 labels", KubernetesVersion: "v1.17.0", NodeLabels: map[string]string{"ingress-ready": "true", "a": "b"}, ExpectedLabels: "a=b,ingress-ready=true"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion: tc.KubernetesVersion,
+				ControlPlane:      true,
+				NodeLabels:        tc.NodeLabels,
+			})
+			assert.ExpectError(t, false, err)
+			for _, doc := range parseDocuments(t, out) {
+				switch doc["kind"] {
+				case "InitConfiguration", "JoinConfiguration":
+					kubeletArgs := doc["nodeRegistration"].(map[string]interface{})["kubeletExtraArgs"].(map[string]interface{})
+					assert.DeepEqual(t, tc.ExpectedLabels, kubeletArgs["node-labels"])
+				}
+			}
+		})
+	}
+}
+
+func parseDocuments(t *testing.T, stream string) []map[string]interface{} {
+	docs := []map[string]interface{}{}
+	for _, raw := range strings.Split(stream, "\n---\n") {
+		doc := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+			t.Fatalf("failed to parse generated config: %v\n%s", err, raw)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
@@ -14,5 +14,6 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package kubeadm contains kubeadm related constants and configuration
+// Package kubeadm contains kubeadm related constants, configuration and
+// node preparation
 package kubeadm
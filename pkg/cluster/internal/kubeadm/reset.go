@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// attemptedMarker is written to nodes before kubeadm init or join runs on
+// them, so that a failed attempt can be undone before it is retried
+const attemptedMarker = "/kind/kubeadm-attempted"
+
+// PrepareNode readies node for running kubeadm init or join
+//
+// If kubeadm has already been run on node by a previous, failed attempt at
+// creating the cluster, the node is reset with `kubeadm reset -f` first, as
+// kubeadm cannot init or join a partially initialized or joined node.
+// The returned bool is true if the node was reset.
+func PrepareNode(logger log.Logger, node nodes.Node) (bool, error) {
+	if node.Command("test", "-f", attemptedMarker).Run() != nil {
+		if err := nodeutils.WriteFile(node, attemptedMarker, ""); err != nil {
+			return false, errors.Wrap(err, "failed to mark kubeadm as attempted")
+		}
+		return false, nil
+	}
+	logger.V(1).Infof("resetting %s after a previous failed kubeadm attempt", node.String())
+	cmd := node.Command("kubeadm", "reset", "--force", "--v=6")
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to reset node with kubeadm")
+	}
+	return true, nil
+}
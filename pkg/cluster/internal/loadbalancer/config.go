@@ -18,53 +18,38 @@ package loadbalancer
 
 import (
 	"bytes"
-	"text/template"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/lbtemplate"
 )
 
 // ConfigData is supplied to the loadbalancer config template
 type ConfigData struct {
 	ControlPlanePort int
-	BackendServers   map[string]string
-	IPv6             bool
+	// BackendServers maps control-plane node names to their API server
+	// host:port address
+	BackendServers map[string]string
+	// IPv6 enables listening on IPv6 in addition to IPv4
+	IPv6 bool
+	// CAFile is the path to the cluster CA certificate on the load balancer,
+	// if unset the API server certificates are not verified
+	CAFile string
+	// ConnectTimeout, ClientTimeout and ServerTimeout are the load balancer
+	// connect, client and server timeouts
+	ConnectTimeout time.Duration
+	ClientTimeout  time.Duration
+	ServerTimeout  time.Duration
+	// StatsPort is the port the statistics page is served on
+	StatsPort int
 }
 
-// DefaultConfigTemplate is the loadbalancer config template
-const DefaultConfigTemplate = `# generated by kind
-global
-  log /dev/log local0
-  log /dev/log local1 notice
-  daemon
-
-defaults
-  log global
-  mode tcp
-  option dontlognull
-  # TODO: tune these
-  timeout connect 5000
-  timeout client 50000
-  timeout server 50000
-
-frontend control-plane
-  bind *:{{ .ControlPlanePort }}
-  {{ if .IPv6 -}}
-  bind :::{{ .ControlPlanePort }};
-  {{- end }}
-  default_backend kube-apiservers
-
-backend kube-apiservers
-  option httpchk GET /healthz
-  # TODO: we should be verifying (!)
-  {{range $server, $address := .BackendServers}}
-  server {{ $server }} {{ $address }} check check-ssl verify none
-  {{- end}}
-`
-
-// Config returns a kubeadm config generated from config data, in particular
-// the kubernetes version
-func Config(data *ConfigData) (config string, err error) {
-	t, err := template.New("loadbalancer-config").Parse(DefaultConfigTemplate)
+// Config returns a load balancer config generated from config data
+// using configTemplate, a text/template such as an Implementation's
+// DefaultConfigTemplate
+func Config(configTemplate string, data *ConfigData) (config string, err error) {
+	t, err := lbtemplate.Parse("loadbalancer-config", configTemplate)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse config template")
 	}
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+	ipv4Servers := map[string]string{
+		"kind-control-plane":  "172.18.0.3:6443",
+		"kind-control-plane2": "172.18.0.4:6443",
+	}
+	ipv6Servers := map[string]string{
+		"kind-control-plane":  "[fc00:f853:ccd:e793::3]:6443",
+		"kind-control-plane2": "[fc00:f853:ccd:e793::4]:6443",
+	}
+	cases := []struct {
+		Name       string
+		Type       config.LoadBalancerType
+		Servers    map[string]string
+		IPv6       bool
+		CAFile     string
+		Expected   []string
+		Unexpected []string
+	}{
+		{
+			Name:    "haproxy IPv4 without CA",
+			Type:    config.HAProxyLoadBalancer,
+			Servers: ipv4Servers,
+			Expected: []string{
+				"bind *:6443\n",
+				"timeout connect 5000",
+				"server kind-control-plane 172.18.0.3:6443 check check-ssl verify none",
+				"server kind-control-plane2 172.18.0.4:6443 check check-ssl verify none",
+				"bind *:8404\n",
+			},
+			Unexpected: []string{"bind :::", "ca-file"},
+		},
+		{
+			Name:    "haproxy IPv6 with CA",
+			Type:    config.HAProxyLoadBalancer,
+			Servers: ipv6Servers,
+			IPv6:    true,
+			CAFile:  "/usr/local/etc/haproxy/ca.crt",
+			Expected: []string{
+				"bind :::6443 v6only",
+				"server kind-control-plane [fc00:f853:ccd:e793::3]:6443 check check-ssl verify required ca-file /usr/local/etc/haproxy/ca.crt",
+				"bind :::8404 v6only",
+			},
+			Unexpected: []string{"verify none"},
+		},
+		{
+			Name:    "envoy IPv4",
+			Type:    config.EnvoyLoadBalancer,
+			Servers: ipv4Servers,
+			CAFile:  "/etc/envoy/ca.crt",
+			Expected: []string{
+				"address: 0.0.0.0\n        port_value: 6443",
+				"connect_timeout: 5s",
+				"idle_timeout: 50s",
+				"address: 172.18.0.3\n                port_value: 6443",
+				"address: 172.18.0.4\n                port_value: 6443",
+			},
+			Unexpected: []string{"ipv4_compat"},
+		},
+		{
+			Name:    "envoy IPv6",
+			Type:    config.EnvoyLoadBalancer,
+			Servers: ipv6Servers,
+			IPv6:    true,
+			Expected: []string{
+				"address: \"::\"\n        port_value: 6443\n        ipv4_compat: true",
+				"address: fc00:f853:ccd:e793::3\n                port_value: 6443",
+			},
+			Unexpected: []string{"[fc00"},
+		},
+		{
+			Name:    "nginx IPv4",
+			Type:    config.NginxLoadBalancer,
+			Servers: ipv4Servers,
+			Expected: []string{
+				"server 172.18.0.3:6443;",
+				"server 172.18.0.4:6443;",
+				"listen 6443;",
+				"proxy_connect_timeout 5000ms;",
+				"proxy_timeout 50000ms;",
+			},
+			Unexpected: []string{"listen [::]"},
+		},
+		{
+			Name:    "nginx IPv6 with CA",
+			Type:    config.NginxLoadBalancer,
+			Servers: ipv6Servers,
+			IPv6:    true,
+			CAFile:  "/etc/nginx/ca.crt",
+			Expected: []string{
+				"server [fc00:f853:ccd:e793::3]:6443;",
+				"listen [::]:6443 ipv6only=on;",
+				"listen [::]:8404 ipv6only=on;",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			impl, err := ForType(tc.Type)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := Config(impl.DefaultConfigTemplate(), &ConfigData{
+				ControlPlanePort: 6443,
+				BackendServers:   tc.Servers,
+				IPv6:             tc.IPv6,
+				CAFile:           tc.CAFile,
+				ConnectTimeout:   5 * time.Second,
+				ClientTimeout:    50 * time.Second,
+				ServerTimeout:    50 * time.Second,
+				StatsPort:        8404,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tc.Expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected config to contain %q, got:\n%s", expected, result)
+				}
+			}
+			for _, unexpected := range tc.Unexpected {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("expected config not to contain %q, got:\n%s", unexpected, result)
+				}
+			}
+		})
+	}
+}
@@ -16,8 +16,5 @@ limitations under the License.
 
 package loadbalancer
 
-// Image defines the loadbalancer image:tag
-const Image = "kindest/haproxy:2.1.1-alpine"
-
-// ConfigPath defines the path to the config file in the image
-const ConfigPath = "/usr/local/etc/haproxy/haproxy.cfg"
+// StatsPort defines the port the statistics page is served on in the image
+const StatsPort = 8404
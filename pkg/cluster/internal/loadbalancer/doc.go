@@ -14,5 +14,6 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package loadbalancer contains external loadbalancer related constants and configuration
+// Package loadbalancer contains external loadbalancer related constants, configuration
+// and reconciliation
 package loadbalancer
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// envoy is an envoy based Implementation
+//
+// envoy cannot reload its bootstrap config, so the image is run with a
+// small script that waits for the config to be written and hot restarts
+// envoy on SIGHUP
+type envoy struct{}
+
+func (envoy) Image() string {
+	return "envoyproxy/envoy:v1.13.1"
+}
+
+func (envoy) Command() []string {
+	return []string{"sh", "-c", envoyRunScript}
+}
+
+func (envoy) ConfigPath() string {
+	return envoyConfigPath
+}
+
+func (envoy) DefaultConfigTemplate() string {
+	return envoyConfigTemplate
+}
+
+// Reload hot restarts envoy, see envoyRunScript
+func (envoy) Reload(n nodes.Node) error {
+	return n.Command("kill", "-s", "HUP", "1").Run()
+}
+
+// envoyConfigPath is the path to the envoy bootstrap config, it is not the
+// image's default so that envoy is only started once kind has written it
+const envoyConfigPath = "/etc/envoy/kind.yaml"
+
+// envoyRunScript starts envoy once the config exists, and starts a new
+// envoy with the next restart epoch on SIGHUP, which takes over the
+// listeners from the previous one
+const envoyRunScript = `epoch=0
+start() {
+  envoy -c ` + envoyConfigPath + ` --restart-epoch "${epoch}" --drain-time-s 5 --parent-shutdown-time-s 10 &
+  epoch=$((epoch + 1))
+}
+trap start HUP
+until [ -f ` + envoyConfigPath + ` ]; do sleep 0.1; done
+start
+while true; do wait; sleep 1; done
+`
+
+// envoyConfigTemplate is the envoy bootstrap config template
+// The API servers are health checked with TCP connections, so they are not
+// verified against the cluster CA. envoy has a single idle timeout, so the
+// client timeout is not used
+const envoyConfigTemplate = `# generated by kind
+admin:
+  access_log_path: /dev/null
+  address:
+    socket_address:
+      address: {{ if .IPv6 }}"::"{{ else }}0.0.0.0{{ end }}
+      port_value: {{ .StatsPort }}
+      {{- if .IPv6 }}
+      ipv4_compat: true
+      {{- end }}
+
+static_resources:
+  listeners:
+  - name: control-plane
+    address:
+      socket_address:
+        address: {{ if .IPv6 }}"::"{{ else }}0.0.0.0{{ end }}
+        port_value: {{ .ControlPlanePort }}
+        {{- if .IPv6 }}
+        ipv4_compat: true
+        {{- end }}
+    filter_chains:
+    - filters:
+      - name: envoy.tcp_proxy
+        typed_config:
+          "@type": type.googleapis.com/envoy.config.filter.network.tcp_proxy.v2.TcpProxy
+          stat_prefix: control-plane
+          cluster: kube-apiservers
+          idle_timeout: {{ seconds .ServerTimeout }}
+
+  clusters:
+  - name: kube-apiservers
+    connect_timeout: {{ seconds .ConnectTimeout }}
+    type: STATIC
+    lb_policy: ROUND_ROBIN
+    health_checks:
+    - timeout: 1s
+      interval: 2s
+      unhealthy_threshold: 3
+      healthy_threshold: 1
+      tcp_health_check: {}
+    load_assignment:
+      cluster_name: kube-apiservers
+      endpoints:
+      - lb_endpoints:
+        {{- range $server, $address := .BackendServers }}
+        # {{ $server }}
+        - endpoint:
+            address:
+              socket_address:
+                address: {{ host $address }}
+                port_value: {{ port $address }}
+        {{- end }}
+`
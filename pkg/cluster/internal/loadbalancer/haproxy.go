@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// haproxy is the default, haproxy based, Implementation
+type haproxy struct{}
+
+func (haproxy) Image() string {
+	return "kindest/haproxy:2.1.1-alpine"
+}
+
+func (haproxy) Command() []string {
+	return nil
+}
+
+func (haproxy) ConfigPath() string {
+	return "/usr/local/etc/haproxy/haproxy.cfg"
+}
+
+func (haproxy) DefaultConfigTemplate() string {
+	return haproxyConfigTemplate
+}
+
+// Reload reloads the config, haproxy will reload on SIGHUP
+func (haproxy) Reload(n nodes.Node) error {
+	return n.Command("kill", "-s", "HUP", "1").Run()
+}
+
+// haproxyConfigTemplate is the haproxy config template
+const haproxyConfigTemplate = `# generated by kind
+global
+  log /dev/log local0
+  log /dev/log local1 notice
+  daemon
+
+defaults
+  log global
+  mode tcp
+  option dontlognull
+  timeout connect {{ milliseconds .ConnectTimeout }}
+  timeout client {{ milliseconds .ClientTimeout }}
+  timeout server {{ milliseconds .ServerTimeout }}
+
+frontend control-plane
+  bind *:{{ .ControlPlanePort }}
+  {{ if .IPv6 -}}
+  bind :::{{ .ControlPlanePort }} v6only
+  {{- end }}
+  default_backend kube-apiservers
+
+backend kube-apiservers
+  option httpchk GET /healthz
+  {{- if not .CAFile }}
+  # the cluster CA does not exist until kubeadm init, so until then
+  # the API servers cannot be verified
+  {{- end }}
+  {{range $server, $address := .BackendServers}}
+  server {{ $server }} {{ $address }} check check-ssl {{ if $.CAFile }}verify required ca-file {{ $.CAFile }}{{ else }}verify none{{ end }}
+  {{- end}}
+
+listen stats
+  mode http
+  bind *:{{ .StatsPort }}
+  {{ if .IPv6 -}}
+  bind :::{{ .StatsPort }} v6only
+  {{- end }}
+  stats enable
+  stats uri /stats
+  stats refresh 10s
+`
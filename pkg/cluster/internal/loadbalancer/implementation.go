@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Implementation is a load balancer implementation, it knows how to run,
+// configure and reload one kind of load balancer
+type Implementation interface {
+	// Image returns the default image to run
+	Image() string
+	// Command returns the command to run the image with,
+	// or nil to use the image's default
+	Command() []string
+	// ConfigPath returns the path to the config file in the image,
+	// the cluster CA is written alongside it as ca.crt
+	ConfigPath() string
+	// DefaultConfigTemplate returns the config template,
+	// which is executed with a ConfigData
+	DefaultConfigTemplate() string
+	// Reload makes the load balancer running on n load its config file
+	Reload(n nodes.Node) error
+}
+
+// ForType returns the Implementation of the load balancer type t
+func ForType(t config.LoadBalancerType) (Implementation, error) {
+	switch t {
+	case config.HAProxyLoadBalancer:
+		return haproxy{}, nil
+	case config.EnvoyLoadBalancer:
+		return envoy{}, nil
+	case config.NginxLoadBalancer:
+		return nginx{}, nil
+	}
+	return nil, errors.Errorf("unknown load balancer type: %q", t)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// nginx is an nginx based Implementation
+//
+// Open source nginx only supports passive health checks, so the API servers
+// are not health checked or verified against the cluster CA
+type nginx struct{}
+
+func (nginx) Image() string {
+	return "nginx:1.17.6-alpine"
+}
+
+func (nginx) Command() []string {
+	return nil
+}
+
+func (nginx) ConfigPath() string {
+	return "/etc/nginx/nginx.conf"
+}
+
+func (nginx) DefaultConfigTemplate() string {
+	return nginxConfigTemplate
+}
+
+func (nginx) Reload(n nodes.Node) error {
+	return n.Command("nginx", "-s", "reload").Run()
+}
+
+// nginxConfigTemplate is the nginx config template
+// nginx has a single idle timeout, so the client timeout is not used
+const nginxConfigTemplate = `# generated by kind
+worker_processes 1;
+
+events {
+  worker_connections 1024;
+}
+
+stream {
+  upstream kube-apiservers {
+    {{- range $server, $address := .BackendServers }}
+    # {{ $server }}
+    server {{ $address }};
+    {{- end }}
+  }
+
+  server {
+    listen {{ .ControlPlanePort }};
+    {{- if .IPv6 }}
+    listen [::]:{{ .ControlPlanePort }} ipv6only=on;
+    {{- end }}
+    proxy_connect_timeout {{ milliseconds .ConnectTimeout }}ms;
+    proxy_timeout {{ milliseconds .ServerTimeout }}ms;
+    proxy_pass kube-apiservers;
+  }
+}
+
+http {
+  server {
+    listen {{ .StatsPort }};
+    {{- if .IPv6 }}
+    listen [::]:{{ .StatsPort }} ipv6only=on;
+    {{- end }}
+    location = /stats {
+      stub_status;
+    }
+  }
+}
+`
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// clusterCAPath is where kubeadm writes the cluster CA certificate
+// on the control-plane nodes
+const clusterCAPath = "/etc/kubernetes/pki/ca.crt"
+
+// Reconcile updates the external load balancer to match the current
+// control-plane nodes in allNodes and cfg, reloading it only if its
+// configuration changed. This should be called whenever control-plane nodes
+// are added or removed, or may have changed IP.
+//
+// The API servers are verified against the cluster CA once it exists.
+//
+// Clusters without an external load balancer are left untouched.
+func Reconcile(allNodes []nodes.Node, cfg *config.Cluster) error {
+	loadBalancerNode, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return err
+	}
+	if loadBalancerNode == nil {
+		return nil
+	}
+
+	impl, err := ForType(cfg.LoadBalancer.Type)
+	if err != nil {
+		return err
+	}
+	configTemplate := cfg.LoadBalancer.ConfigTemplate
+	if configTemplate == "" {
+		configTemplate = impl.DefaultConfigTemplate()
+	}
+	configPath := impl.ConfigPath()
+	caPath := path.Join(path.Dir(configPath), "ca.crt")
+
+	data, err := configData(allNodes, cfg)
+	if err != nil {
+		return err
+	}
+
+	// copy the cluster CA to the load balancer, if kubeadm has created it
+	changed := false
+	bootstrapNode, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	ca, err := readFile(bootstrapNode, clusterCAPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the cluster CA")
+	}
+	if ca != "" {
+		current, err := readFile(loadBalancerNode, caPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to read loadbalancer CA")
+		}
+		if current != ca {
+			if err := nodeutils.WriteFile(loadBalancerNode, caPath, ca); err != nil {
+				return errors.Wrap(err, "failed to copy the cluster CA to the loadbalancer")
+			}
+			changed = true
+		}
+		data.CAFile = caPath
+	}
+
+	// create loadbalancer config on the node if it changed
+	loadbalancerConfig, err := Config(configTemplate, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate loadbalancer config data")
+	}
+	current, err := readFile(loadBalancerNode, configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read loadbalancer config")
+	}
+	if current != loadbalancerConfig {
+		if err := nodeutils.WriteFile(loadBalancerNode, configPath, loadbalancerConfig); err != nil {
+			return errors.Wrap(err, "failed to copy loadbalancer config to node")
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	// reload the config
+	if err := impl.Reload(loadBalancerNode); err != nil {
+		return errors.Wrap(err, "failed to reload loadbalancer")
+	}
+	return nil
+}
+
+// configData computes the load balancer ConfigData for the current
+// control-plane nodes in allNodes and cfg
+func configData(allNodes []nodes.Node, cfg *config.Cluster) (*ConfigData, error) {
+	// obtain IP family
+	// dual stack clusters use IPv4 backends, but listen on both families
+	ipv6 := cfg.Networking.IPFamily == config.IPv6Family
+	bindIPv6 := ipv6 || cfg.Networking.IPFamily == config.DualStackFamily
+
+	// collect info about the existing controlplane nodes
+	var backendServers = map[string]string{}
+	controlPlaneNodes, err := nodeutils.SelectNodesByRole(
+		allNodes,
+		constants.ControlPlaneNodeRoleValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range controlPlaneNodes {
+		controlPlaneIPv4, controlPlaneIPv6, err := n.IP()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get IP for node %s", n.String())
+		}
+		if controlPlaneIPv4 != "" && !ipv6 {
+			backendServers[n.String()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, common.APIServerInternalPort)
+		}
+		if controlPlaneIPv6 != "" && ipv6 {
+			backendServers[n.String()] = fmt.Sprintf("[%s]:%d", controlPlaneIPv6, common.APIServerInternalPort)
+		}
+	}
+
+	data := &ConfigData{
+		ControlPlanePort: common.APIServerInternalPort,
+		BackendServers:   backendServers,
+		IPv6:             bindIPv6,
+		StatsPort:        StatsPort,
+	}
+	for _, timeout := range []struct {
+		value string
+		out   *time.Duration
+	}{
+		{cfg.LoadBalancer.ConnectTimeout, &data.ConnectTimeout},
+		{cfg.LoadBalancer.ClientTimeout, &data.ClientTimeout},
+		{cfg.LoadBalancer.ServerTimeout, &data.ServerTimeout},
+	} {
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid loadbalancer timeout")
+		}
+		*timeout.out = d
+	}
+	return data, nil
+}
+
+// readFile returns the contents of path on node n, or "" if it does not exist
+func readFile(n nodes.Node, path string) (string, error) {
+	// only a missing file is expected, any other failure to read it is an error
+	var buff bytes.Buffer
+	cmd := n.Command("sh", "-c", `[ ! -e "$1" ] || cat "$1"`, "sh", path)
+	if err := cmd.SetStdout(&buff).Run(); err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// archiveOutput writes the logs to a gzip compressed tar archive
+type archiveOutput struct {
+	mu sync.Mutex
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func newArchiveOutput(w io.Writer) *archiveOutput {
+	gw := gzip.NewWriter(w)
+	return &archiveOutput{
+		gw: gw,
+		tw: tar.NewWriter(gw),
+	}
+}
+
+// writeEntry copies the entry to the archive, entries are written whole
+// so that concurrent collectors do not interleave
+func (a *archiveOutput) writeEntry(hdr *tar.Header, r io.Reader) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+	_, err := io.CopyN(a.tw, r, hdr.Size)
+	return err
+}
+
+// writeFile spools the contents to a temporary file, as the size of each
+// entry must be known before writing it
+func (a *archiveOutput) writeFile(path string, write func(io.Writer) error) error {
+	return spoolFile(path, write, a.writeEntry)
+}
+
+// Close finishes writing the archive, it does not close the underlying writer
+func (a *archiveOutput) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gw.Close()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestArchiveOutput(t *testing.T) {
+	t.Parallel()
+	var archive bytes.Buffer
+	out := newArchiveOutput(&archive)
+	limited := &limitedOutput{output: out, maxSize: 5}
+	files := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{
+			name:     "kind-control-plane/kubelet.log",
+			contents: "short",
+			want:     "short",
+		},
+		{
+			name:     "kind-control-plane/journal.log",
+			contents: "only the end",
+			want:     "e end",
+		},
+	}
+	if err := limited.writeEntry(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "kind-control-plane",
+		Mode:     0755,
+	}, nil); err != nil {
+		t.Fatalf("unexpected error writing dir: %v", err)
+	}
+	for _, f := range files {
+		contents := f.contents
+		if err := limited.writeFile(f.name, func(w io.Writer) error {
+			_, err := io.WriteString(w, contents)
+			return err
+		}); err != nil {
+			t.Fatalf("unexpected error writing %s: %v", f.name, err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("unexpected error closing archive: %v", err)
+	}
+
+	gr, err := gzip.NewReader(&archive)
+	if err != nil {
+		t.Fatalf("archive is not gzip compressed: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("unexpected error reading dir: %v", err)
+	}
+	if hdr.Typeflag != tar.TypeDir || hdr.Name != "kind-control-plane" {
+		t.Errorf("expected dir kind-control-plane, got %v %q", hdr.Typeflag, hdr.Name)
+	}
+	for _, f := range files {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatalf("unexpected error reading %s: %v", f.name, err)
+		}
+		if hdr.Name != f.name {
+			t.Errorf("expected %s, got %s", f.name, hdr.Name)
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("unexpected error reading %s: %v", f.name, err)
+		}
+		if string(contents) != f.want {
+			t.Errorf("expected %s to contain %q, got %q", f.name, f.want, string(contents))
+		}
+	}
+}
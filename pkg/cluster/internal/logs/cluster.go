@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"io"
+	"path"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/version"
+)
+
+// clusterDir is the directory in the logs that cluster level state is
+// collected to
+const clusterDir = "cluster"
+
+// clusterResources are the resources collected with `kubectl get`,
+// secrets are deliberately not collected
+var clusterResources = []string{
+	"nodes",
+	"namespaces",
+	"pods",
+	"services",
+	"endpoints",
+	"configmaps",
+	"serviceaccounts",
+	"persistentvolumes",
+	"persistentvolumeclaims",
+	"storageclasses",
+	"daemonsets",
+	"deployments",
+	"replicasets",
+	"statefulsets",
+	"jobs",
+	"events",
+}
+
+// clusterStateFns returns functions collecting the kind version, the
+// effective cluster config and the Kubernetes API state of the cluster from
+// the bootstrap control plane node in allNodes
+//
+// The cluster may be broken, which is often why the logs are being
+// collected, so failing to collect the cluster state is only logged
+func clusterStateFns(logger log.Logger, allNodes []nodes.Node, out output) []func() error {
+	fns := []func() error{
+		func() error {
+			return out.writeFile(path.Join(clusterDir, "kind-version.txt"), func(w io.Writer) error {
+				_, err := io.WriteString(w, version.DisplayVersion()+"\n")
+				return err
+			})
+		},
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		logger.Warnf("Not collecting cluster state: %v", err)
+		return fns
+	}
+	// helper to run a cmd and write the output to path in the cluster dir,
+	// logging failures
+	execToPathFn := func(cmd exec.Cmd, name string) func() error {
+		return func() error {
+			return out.writeFile(path.Join(clusterDir, name), func(w io.Writer) error {
+				cmd.SetStdout(w)
+				cmd.SetStderr(w)
+				if err := cmd.Run(); err != nil {
+					logger.Warnf("Failed to collect cluster %s: %v", name, err)
+				}
+				return nil
+			})
+		}
+	}
+	kubectl := func(args ...string) exec.Cmd {
+		return node.Command("kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...)
+	}
+	fns = append(fns,
+		execToPathFn(node.Command("cat", "/kind/kubeadm.conf"), "kubeadm.conf"),
+		execToPathFn(node.Command("cat", "/kind/cluster-config.yaml"), "cluster-config.yaml"),
+		execToPathFn(kubectl("describe", "nodes"), "nodes-describe.txt"),
+	)
+	for _, resource := range clusterResources {
+		fns = append(fns, execToPathFn(
+			kubectl("get", "--all-namespaces", "-o", "yaml", resource),
+			path.Join("resources", resource+".yaml"),
+		))
+	}
+	return fns
+}
@@ -20,9 +20,13 @@ import (
 	"archive/tar"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/alessio/shellescape"
 
@@ -32,80 +36,199 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 )
 
+// Components that can be selected with Options.Components
+const (
+	// NodeComponent is the node container's docker inspect output,
+	// serial log and Kubernetes version, and the host's docker info and
+	// routes
+	NodeComponent = "node"
+	// JournalComponent is the full journal of each node
+	JournalComponent = "journal"
+	// KubeletComponent is the kubelet journal of each node
+	KubeletComponent = "kubelet"
+	// ContainerdComponent is the containerd journal of each node
+	ContainerdComponent = "containerd"
+	// PodsComponent is the pod and container logs in /var/log of each node
+	PodsComponent = "pods"
+	// VarLogComponent is all of /var/log of each node, including the pods
+	VarLogComponent = "varlog"
+	// ClusterComponent is the kind version, the effective cluster config,
+	// the kubeadm config and the Kubernetes API state of the cluster
+	ClusterComponent = "cluster"
+)
+
+// Components returns all of the components that can be collected
+func Components() []string {
+	return []string{
+		NodeComponent,
+		JournalComponent,
+		KubeletComponent,
+		ContainerdComponent,
+		PodsComponent,
+		VarLogComponent,
+		ClusterComponent,
+	}
+}
+
+// Options filter the logs collected by Collect and CollectArchive
+type Options struct {
+	// Since limits logs to those written in this long before collecting,
+	// if zero all logs are collected
+	Since time.Duration
+	// Nodes limits the collected nodes to those with these names,
+	// if empty all nodes are collected
+	Nodes []string
+	// Components limits the collected logs to these components,
+	// if empty all components are collected
+	Components []string
+	// MaxSize limits each collected file to its last MaxSize bytes,
+	// if zero files are not limited
+	MaxSize int64
+}
+
+func (o *Options) collects(component string) bool {
+	if len(o.Components) == 0 {
+		return true
+	}
+	for _, c := range o.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
 // Collect collects logs related to / from the cluster nodes and the host
 // system to the specified directory
-func Collect(logger log.Logger, nodes []nodes.Node, dir string) error {
-	prefixedPath := func(path string) string {
-		return filepath.Join(dir, path)
+func Collect(logger log.Logger, nodes []nodes.Node, dir string, opts *Options) error {
+	return collect(logger, nodes, &dirOutput{logger: logger, dir: dir}, opts)
+}
+
+// CollectArchive is like Collect, but writes the logs to w as a gzip
+// compressed tar archive, without writing them to disk first
+func CollectArchive(logger log.Logger, nodes []nodes.Node, w io.Writer, opts *Options) error {
+	out := newArchiveOutput(w)
+	err := collect(logger, nodes, out, opts)
+	if closeErr := out.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "failed to finish writing logs archive")
 	}
-	// helper to run a cmd and write the output to path
-	execToPath := func(cmd exec.Cmd, path string) error {
-		realPath := prefixedPath(path)
-		if err := os.MkdirAll(filepath.Dir(realPath), os.ModePerm); err != nil {
-			return err
-		}
-		f, err := os.Create(realPath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		cmd.SetStdout(f)
-		cmd.SetStderr(f)
-		return cmd.Run()
+	return err
+}
+
+func collect(logger log.Logger, allNodes []nodes.Node, out output, opts *Options) error {
+	selected, err := selectNodes(allNodes, opts.Nodes)
+	if err != nil {
+		return err
 	}
+	out = &limitedOutput{output: out, maxSize: opts.MaxSize}
+
+	// helper to run a cmd and write the output to path
 	execToPathFn := func(cmd exec.Cmd, path string) func() error {
 		return func() error {
-			return execToPath(cmd, path)
+			return out.writeFile(path, func(w io.Writer) error {
+				cmd.SetStdout(w)
+				cmd.SetStderr(w)
+				return cmd.Run()
+			})
 		}
 	}
+	// the node logs are limited to those since the same point in time
+	var since int64
+	if opts.Since != 0 {
+		since = time.Now().Add(-opts.Since).Unix()
+	}
+	journalArgs := []string{"--no-pager"}
+	dockerLogsArgs := []string{"logs"}
+	if since != 0 {
+		journalArgs = append(journalArgs, fmt.Sprintf("--since=@%d", since))
+		dockerLogsArgs = append(dockerLogsArgs, fmt.Sprintf("--since=%d", since))
+	}
+
 	// construct a slice of methods to collect logs
-	fns := []func() error{
-		// TODO(bentheelder): record the kind version here as well
+	fns := []func() error{}
+	if opts.collects(ClusterComponent) {
+		// the cluster state is collected from all nodes, not just selected
+		fns = append(fns, clusterStateFns(logger, allNodes, out)...)
+	}
+	if opts.collects(NodeComponent) {
 		// record info about the host docker
-		execToPathFn(
+		fns = append(fns, execToPathFn(
 			exec.Command("docker", "info"),
 			"docker-info.txt",
-		),
+		))
+		// record the host routes, to check the node network against them
+		// NOTE: on other platforms the nodes run in a VM, with its own routes
+		if runtime.GOOS == "linux" {
+			routes := execToPathFn(
+				exec.Command("sh", "-c", "ip route show; ip -6 route show"),
+				"host-routes.txt",
+			)
+			fns = append(fns, func() error {
+				if err := routes(); err != nil {
+					logger.Warnf("Failed to collect the host routes: %v", err)
+				}
+				return nil
+			})
+		}
 	}
 
 	// collect /var/log for each node and plan collecting more logs
 	errs := []error{}
-	for _, n := range nodes {
+	for _, n := range selected {
 		node := n // https://golang.org/doc/faq#closures_and_goroutines
 		name := node.String()
-		if err := dumpDir(logger, n, "/var/log", filepath.Join(dir, name)); err != nil {
-			errs = append(errs, err)
+		// all of /var/log includes the pod logs
+		var varLogPaths []string
+		if opts.collects(VarLogComponent) {
+			varLogPaths = []string{"."}
+		} else if opts.collects(PodsComponent) {
+			varLogPaths = []string{"./pods", "./containers"}
+		}
+		if len(varLogPaths) > 0 {
+			if err := dumpDir(node, "/var/log", varLogPaths, since, name, out); err != nil {
+				errs = append(errs, err)
+			}
 		}
 
-		fns = append(fns, func() error {
-			return errors.AggregateConcurrent([]func() error{
+		nodeFns := []func() error{}
+		if opts.collects(NodeComponent) {
+			nodeFns = append(nodeFns,
 				// record info about the node container
 				execToPathFn(
 					exec.Command("docker", "inspect", name),
-					filepath.Join(name, "inspect.json"),
+					path.Join(name, "inspect.json"),
 				),
 				// grab all of the node logs
 				execToPathFn(
-					exec.Command("docker", "logs", name),
-					filepath.Join(name, "serial.log"),
+					exec.Command("docker", append(dockerLogsArgs, name)...),
+					path.Join(name, "serial.log"),
 				),
 				execToPathFn(
 					node.Command("cat", "/kind/version"),
-					filepath.Join(name, "kubernetes-version.txt"),
+					path.Join(name, "kubernetes-version.txt"),
 				),
-				execToPathFn(
-					node.Command("journalctl", "--no-pager"),
-					filepath.Join(name, "journal.log"),
-				),
-				execToPathFn(
-					node.Command("journalctl", "--no-pager", "-u", "kubelet.service"),
-					filepath.Join(name, "kubelet.log"),
-				),
-				execToPathFn(
-					node.Command("journalctl", "--no-pager", "-u", "containerd.service"),
-					filepath.Join(name, "containerd.log"),
-				),
-			})
+			)
+		}
+		if opts.collects(JournalComponent) {
+			nodeFns = append(nodeFns, execToPathFn(
+				node.Command("journalctl", journalArgs...),
+				path.Join(name, "journal.log"),
+			))
+		}
+		if opts.collects(KubeletComponent) {
+			nodeFns = append(nodeFns, execToPathFn(
+				node.Command("journalctl", append(journalArgs, "-u", "kubelet.service")...),
+				path.Join(name, "kubelet.log"),
+			))
+		}
+		if opts.collects(ContainerdComponent) {
+			nodeFns = append(nodeFns, execToPathFn(
+				node.Command("journalctl", append(journalArgs, "-u", "containerd.service")...),
+				path.Join(name, "containerd.log"),
+			))
+		}
+		fns = append(fns, func() error {
+			return errors.AggregateConcurrent(nodeFns)
 		})
 	}
 
@@ -114,8 +237,41 @@ func Collect(logger log.Logger, nodes []nodes.Node, dir string) error {
 	return errors.NewAggregate(errs)
 }
 
-// dumpDir dumps the dir nodeDir on the node to the dir hostDir on the host
-func dumpDir(logger log.Logger, node nodes.Node, nodeDir, hostDir string) (err error) {
+// selectNodes returns the nodes in allNodes named in names,
+// or allNodes if names is empty
+func selectNodes(allNodes []nodes.Node, names []string) ([]nodes.Node, error) {
+	if len(names) == 0 {
+		return allNodes, nil
+	}
+	selected := []nodes.Node{}
+	for _, name := range names {
+		found := false
+		for _, n := range allNodes {
+			if n.String() == name {
+				selected = append(selected, n)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("unknown node %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// dumpDir dumps paths under the dir nodeDir on the node to the dir hostDir
+// in out, only including files modified since the unix time since, if set
+func dumpDir(node nodes.Node, nodeDir string, paths []string, since int64, hostDir string, out output) (err error) {
+	args := []string{"--hard-dereference", "-C", path.Clean(nodeDir) + "/", "-chf", "-", "--ignore-failed-read"}
+	if since != 0 {
+		args = append(args, fmt.Sprintf("--newer-mtime=@%d", since))
+	}
+	args = append(args, paths...)
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellescape.Quote(arg)
+	}
 	cmd := node.Command(
 		"sh", "-c",
 		// Tar will exit 1 if a file changed during the archival.
@@ -124,61 +280,149 @@ func dumpDir(logger log.Logger, node nodes.Node, nodeDir, hostDir string) (err e
 		// Fatal errors will return exit code 2.
 		// http://man7.org/linux/man-pages/man1/tar.1.html#RETURN_VALUE
 		fmt.Sprintf(
-			`tar --hard-dereference -C %s -chf - . || (r=$?; [ $r -eq 1 ] || exit $r)`,
-			shellescape.Quote(path.Clean(nodeDir)+"/"),
+			`tar %s || (r=$?; [ $r -eq 1 ] || exit $r)`,
+			strings.Join(quoted, " "),
 		),
 	)
 
 	return exec.RunWithStdoutReader(cmd, func(outReader io.Reader) error {
-		if err := untar(logger, outReader, hostDir); err != nil {
-			return errors.Wrapf(err, "Untarring %q: %v", nodeDir, err)
+		tr := tar.NewReader(outReader)
+		for {
+			hdr, err := tr.Next()
+			switch {
+			case err == io.EOF:
+				return nil
+			case err != nil:
+				return errors.Wrapf(err, "failed reading %q from %s", nodeDir, node.String())
+			}
+			hdr.Name = path.Join(hostDir, hdr.Name)
+			if err := out.writeEntry(hdr, tr); err != nil {
+				return errors.Wrapf(err, "failed writing %q from %s", nodeDir, node.String())
+			}
 		}
-		return nil
 	})
 }
 
-// untar reads the tar file from r and writes it into dir.
-func untar(logger log.Logger, r io.Reader, dir string) (err error) {
-	tr := tar.NewReader(r)
-	for {
-		f, err := tr.Next()
+// output receives the collected logs, named relative to the root of the
+// output, its methods must be safe to call concurrently
+type output interface {
+	// writeEntry writes the tar entry hdr with the contents read from r
+	writeEntry(hdr *tar.Header, r io.Reader) error
+	// writeFile writes a regular file at path with the contents written by
+	// write, what was written is kept even if write fails
+	writeFile(path string, write func(io.Writer) error) error
+}
+
+// spoolFile writes the contents written by write to a temporary file, and
+// then to a regular file entry at path with writeEntry
+// this is used where the size must be known before writing the contents,
+// without holding them in memory
+func spoolFile(path string, write func(io.Writer) error, writeEntry func(*tar.Header, io.Reader) error) error {
+	f, err := ioutil.TempFile("", "kind-logs-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	writeErr := write(f)
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := writeEntry(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path,
+		Mode:     0644,
+		Size:     info.Size(),
+		ModTime:  time.Now(),
+	}, f); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// limitedOutput is an output that only writes the last maxSize bytes of each
+// regular file to output, if maxSize is set
+type limitedOutput struct {
+	output
+	maxSize int64
+}
 
-		switch {
-		case err == io.EOF:
-			return nil
-		case err != nil:
-			return errors.Wrapf(err, "tar reading error: %v", err)
-		case f == nil:
-			continue
+func (l *limitedOutput) writeEntry(hdr *tar.Header, r io.Reader) error {
+	if l.maxSize > 0 && hdr.Typeflag == tar.TypeReg && hdr.Size > l.maxSize {
+		if _, err := io.CopyN(ioutil.Discard, r, hdr.Size-l.maxSize); err != nil {
+			return err
 		}
+		limited := *hdr
+		limited.Size = l.maxSize
+		hdr = &limited
+	}
+	return l.output.writeEntry(hdr, r)
+}
 
-		rel := filepath.FromSlash(f.Name)
-		abs := filepath.Join(dir, rel)
+func (l *limitedOutput) writeFile(path string, write func(io.Writer) error) error {
+	if l.maxSize <= 0 {
+		return l.output.writeFile(path, write)
+	}
+	// the size is needed to only keep the end of the file
+	return spoolFile(path, write, l.writeEntry)
+}
+
+// dirOutput writes the logs to dir on the host
+type dirOutput struct {
+	logger log.Logger
+	dir    string
+}
 
-		switch f.Typeflag {
-		case tar.TypeReg:
-			wf, err := os.OpenFile(abs, os.O_CREATE|os.O_RDWR, os.FileMode(f.Mode))
-			if err != nil {
+func (d *dirOutput) writeEntry(hdr *tar.Header, r io.Reader) error {
+	abs := filepath.Join(d.dir, filepath.FromSlash(hdr.Name))
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(abs), os.ModePerm); err != nil {
+			return err
+		}
+		wf, err := os.OpenFile(abs, os.O_CREATE|os.O_TRUNC|os.O_RDWR, os.FileMode(hdr.Mode))
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(wf, r)
+		if closeErr := wf.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return errors.Errorf("error writing to %s: %v", abs, err)
+		}
+		if n != hdr.Size {
+			return errors.Errorf("only wrote %d bytes to %s; expected %d", n, abs, hdr.Size)
+		}
+	case tar.TypeDir:
+		if _, err := os.Stat(abs); err != nil {
+			if err := os.MkdirAll(abs, 0755); err != nil {
 				return err
 			}
-			n, err := io.Copy(wf, tr)
-			if closeErr := wf.Close(); closeErr != nil && err == nil {
-				err = closeErr
-			}
-			if err != nil {
-				return errors.Errorf("error writing to %s: %v", abs, err)
-			}
-			if n != f.Size {
-				return errors.Errorf("only wrote %d bytes to %s; expected %d", n, abs, f.Size)
-			}
-		case tar.TypeDir:
-			if _, err := os.Stat(abs); err != nil {
-				if err := os.MkdirAll(abs, 0755); err != nil {
-					return err
-				}
-			}
-		default:
-			logger.Warnf("tar file entry %s contained unsupported file type %v", f.Name, f.Typeflag)
 		}
+	default:
+		d.logger.Warnf("tar file entry %s contained unsupported file type %v", hdr.Name, hdr.Typeflag)
+	}
+	return nil
+}
+
+// writeFile streams the contents straight to the file
+func (d *dirOutput) writeFile(path string, write func(io.Writer) error) error {
+	abs := filepath.Join(d.dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(abs), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(abs, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = errors.Errorf("error writing to %s: %v", abs, closeErr)
 	}
+	return err
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestDirOutputWriteFile(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		MaxSize     int64
+		WriteErr    error
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "streamed",
+			Expected: "only the end",
+		},
+		{
+			Name:     "limited",
+			MaxSize:  5,
+			Expected: "e end",
+		},
+		{
+			Name:        "kept on failure",
+			WriteErr:    errors.New("command failed"),
+			Expected:    "only the end",
+			ExpectError: true,
+		},
+		{
+			Name:        "limited and kept on failure",
+			MaxSize:     5,
+			WriteErr:    errors.New("command failed"),
+			Expected:    "e end",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := ioutil.TempDir("", "kind-logs-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			out := &limitedOutput{output: &dirOutput{logger: log.NoopLogger{}, dir: dir}, maxSize: tc.MaxSize}
+			err = out.writeFile("kind-control-plane/journal.log", func(w io.Writer) error {
+				if _, err := io.WriteString(w, "only the end"); err != nil {
+					return err
+				}
+				return tc.WriteErr
+			})
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "kind-control-plane", "journal.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(contents) != tc.Expected {
+				t.Errorf("expected %q, got %q", tc.Expected, string(contents))
+			}
+		})
+	}
+}
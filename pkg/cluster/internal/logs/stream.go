@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// StreamOptions select the journal streamed by Stream
+type StreamOptions struct {
+	// Nodes limits the streamed nodes to those with these names,
+	// if empty all nodes are streamed
+	Nodes []string
+	// Units limits the journal to these systemd units, eg kubelet,
+	// if empty the full journal is streamed
+	Units []string
+	// Follow keeps streaming new journal entries until interrupted
+	Follow bool
+}
+
+// maxLineSize is the longest journal line Stream will handle
+const maxLineSize = 1024 * 1024
+
+// Stream streams the journal of the selected nodes concurrently, calling
+// handle with the node name for each line. Calls to handle are serialized.
+func Stream(allNodes []nodes.Node, opts *StreamOptions, handle func(node, line string)) error {
+	selected, err := selectNodes(allNodes, opts.Nodes)
+	if err != nil {
+		return err
+	}
+	args := []string{"--no-pager"}
+	for _, unit := range opts.Units {
+		args = append(args, "-u", unit)
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+
+	var handleMu sync.Mutex
+	fns := []func() error{}
+	for _, n := range selected {
+		node := n // https://golang.org/doc/faq#closures_and_goroutines
+		name := node.String()
+		fns = append(fns, func() error {
+			cmd := node.Command("journalctl", args...)
+			return exec.RunWithStdoutReader(cmd, func(r io.Reader) error {
+				scanner := bufio.NewScanner(r)
+				scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+				for scanner.Scan() {
+					handleMu.Lock()
+					handle(name, scanner.Text())
+					handleMu.Unlock()
+				}
+				if err := scanner.Err(); err != nil {
+					return errors.Wrapf(err, "failed reading journal from %s", name)
+				}
+				return nil
+			})
+		})
+	}
+	return errors.AggregateConcurrent(fns)
+}
@@ -35,45 +35,81 @@ import (
 // Patches match if their kind and apiVersion match a document, with the exception
 // that if the patch does not set apiVersion it will be ignored.
 func KubeYAML(toPatch string, patches []string, patches6902 []config.PatchJSON6902) (string, error) {
+	patched, _, err := KubeYAMLWithUnmatched(toPatch, patches, patches6902)
+	return patched, err
+}
+
+// Unmatched contains the indexes of the patches that did not match
+// any document, see KubeYAMLWithUnmatched
+type Unmatched struct {
+	// Patches are indexes into the merge patches
+	Patches []int
+	// PatchesJSON6902 are indexes into the JSON 6902 patches
+	PatchesJSON6902 []int
+}
+
+// KubeYAMLWithUnmatched is like KubeYAML, but additionally returns the
+// patches that did not match any of the documents.
+//
+// Each document has all of the merge patches applied in order, followed by
+// all of the JSON 6902 patches in order.
+func KubeYAMLWithUnmatched(toPatch string, patches []string, patches6902 []config.PatchJSON6902) (string, Unmatched, error) {
 	// pre-process, including splitting up documents etc.
 	resources, err := parseResources(toPatch)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse yaml to patch")
+		return "", Unmatched{}, errors.Wrap(err, "failed to parse yaml to patch")
 	}
 	mergePatches, err := parseMergePatches(patches)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse patches")
+		return "", Unmatched{}, errors.Wrap(err, "failed to parse patches")
 	}
 	json6902patches, err := convertJSON6902Patches(patches6902)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse JSON 6902 patches")
+		return "", Unmatched{}, errors.Wrap(err, "failed to parse JSON 6902 patches")
 	}
 	// apply patches and build result
+	mergeMatched := make([]bool, len(mergePatches))
+	json6902Matched := make([]bool, len(json6902patches))
 	builder := &strings.Builder{}
 	for i, r := range resources {
 		// apply merge patches
-		for _, p := range mergePatches {
-			if _, err := r.applyMergePatch(p); err != nil {
-				return "", errors.Wrap(err, "failed to apply patch")
+		for j, p := range mergePatches {
+			matches, err := r.applyMergePatch(p)
+			if err != nil {
+				return "", Unmatched{}, errors.Wrap(err, "failed to apply patch")
 			}
+			mergeMatched[j] = mergeMatched[j] || matches
 		}
 		// apply RFC 6902 JSON patches
-		for _, p := range json6902patches {
-			if _, err := r.apply6902Patch(p); err != nil {
-				return "", errors.Wrap(err, "failed to apply JSON 6902 patch")
+		for j, p := range json6902patches {
+			matches, err := r.apply6902Patch(p)
+			if err != nil {
+				return "", Unmatched{}, errors.Wrap(err, "failed to apply JSON 6902 patch")
 			}
+			json6902Matched[j] = json6902Matched[j] || matches
 		}
 		// write out result
 		if err := r.encodeTo(builder); err != nil {
-			return "", errors.Wrap(err, "failed to write patched resource")
+			return "", Unmatched{}, errors.Wrap(err, "failed to write patched resource")
 		}
 		// write document separator
 		if i+1 < len(resources) {
 			if _, err := builder.WriteString("---\n"); err != nil {
-				return "", errors.Wrap(err, "failed to write document separator")
+				return "", Unmatched{}, errors.Wrap(err, "failed to write document separator")
 			}
 		}
 	}
-	// verify that all patches were used
-	return builder.String(), nil
+	// report any patches that were not used
+	unmatched := Unmatched{}
+	for i, matched := range mergeMatched {
+		if !matched {
+			unmatched.Patches = append(unmatched.Patches, i)
+		}
+	}
+	for i, matched := range json6902Matched {
+		if !matched {
+			unmatched.PatchesJSON6902 = append(unmatched.PatchesJSON6902, i)
+		}
+	}
+	return builder.String(), unmatched, nil
 }
@@ -75,6 +75,25 @@ func TestKubeYAML(t *testing.T) {
 	}
 }
 
+func TestKubeYAMLWithUnmatched(t *testing.T) {
+	t.Parallel()
+	unmatchedPatch := "apiVersion: kubeadm.k8s.io/v1beta2\nkind: BogusConfiguration\n"
+	unmatchedPatch6902 := config.PatchJSON6902{
+		Group:   "kubeadm.k8s.io",
+		Version: "v1beta1",
+		Kind:    "ClusterConfiguration",
+		Patch:   trivialPatch6902.Patch,
+	}
+	out, unmatched, err := KubeYAMLWithUnmatched(
+		normalKubeadmConfig,
+		[]string{unmatchedPatch, trivialPatch},
+		[]config.PatchJSON6902{trivialPatch6902, unmatchedPatch6902},
+	)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, normalKubeadmConfigTrivialPatchedAnd6902Patched, out)
+	assert.DeepEqual(t, Unmatched{Patches: []int{0}, PatchesJSON6902: []int{1}}, unmatched)
+}
+
 const normalKubeadmConfig = `# config generated by kind
 apiVersion: kubeadm.k8s.io/v1beta2
 kind: ClusterConfiguration
@@ -57,7 +57,7 @@ func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Clu
 	// actually provision the cluster
 	icons := strings.Repeat("📦 ", len(cfg.Nodes))
 	status.Start(fmt.Sprintf("Preparing nodes %s", icons))
-	defer func() { status.End(err == nil) }()
+	defer func() { status.EndWithError(err) }()
 
 	// plan creating the containers
 	createContainerFuncs, err := planCreation(cluster, cfg)
@@ -19,6 +19,7 @@ package docker
 import (
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -51,16 +52,38 @@ func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []f
 		}
 		// plan loadbalancer node
 		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		loadBalancerArgs, err := runArgsForLoadBalancer(cfg, name, genericArgs)
+		if err != nil {
+			return nil, err
+		}
 		createContainerFuncs = append(createContainerFuncs, func() error {
-			return createContainer(runArgsForLoadBalancer(cfg, name, genericArgs))
+			return createContainer(loadBalancerArgs)
 		})
 	}
 
+	// persistent storage is kept in a directory per cluster on the host
+	var storageMount *config.Mount
+	if cfg.Storage.PersistentHostPath != "" {
+		hostPath := filepath.Join(cfg.Storage.PersistentHostPath, cluster)
+		if err := os.MkdirAll(hostPath, 0755); err != nil {
+			return nil, errors.Wrapf(err, "failed to create persistent storage directory %q", hostPath)
+		}
+		storageMount = &config.Mount{
+			HostPath:      hostPath,
+			ContainerPath: cfg.Storage.LocalPath,
+		}
+	}
+
 	// plan normal nodes
 	for _, node := range cfg.Nodes {
 		node := node.DeepCopy()              // copy so we can modify
 		name := nodeNamer(string(node.Role)) // name the node
 
+		// every node needs the persistent storage
+		if storageMount != nil {
+			node.ExtraMounts = append(node.ExtraMounts, *storageMount)
+		}
+
 		// fixup relative paths, docker can only handle absolute paths
 		for i := range node.ExtraMounts {
 			hostPath := node.ExtraMounts[i].HostPath
@@ -106,6 +129,11 @@ func clusterIsIPv6(cfg *config.Cluster) bool {
 	return cfg.Networking.IPFamily == "ipv6"
 }
 
+// clusterHasIPv6 returns true for IPv6 and dual stack clusters
+func clusterHasIPv6(cfg *config.Cluster) bool {
+	return clusterIsIPv6(cfg) || cfg.Networking.IPFamily == "dual"
+}
+
 func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 	controlPlanes := 0
 	for _, configNode := range cfg.Nodes {
@@ -129,7 +157,7 @@ func commonArgs(cluster string, cfg *config.Cluster) ([]string, error) {
 	}
 
 	// enable IPv6 if necessary
-	if clusterIsIPv6(cfg) {
+	if clusterHasIPv6(cfg) {
 		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
 	}
 
@@ -182,13 +210,13 @@ func runArgsForNode(node *config.Node, name string, args []string) []string {
 
 	// convert mounts and port mappings to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
-	args = append(args, generatePortMappings(node.ExtraPortMappings...)...)
+	args = append(args, generatePortMappings(node.PortMappings()...)...)
 
 	// finally, specify the image to run
 	return append(args, node.Image)
 }
 
-func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) []string {
+func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
 	args = append([]string{
 		"run",
 		"--hostname", name, // make hostname match container name
@@ -207,8 +235,27 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) []s
 		ContainerPort: common.APIServerInternalPort,
 	})...)
 
-	// finally, specify the image to run
-	return append(args, loadbalancer.Image)
+	// optionally publish the statistics page
+	if cfg.LoadBalancer.StatsPort != 0 {
+		args = append(args, generatePortMappings(config.PortMapping{
+			ListenAddress: cfg.Networking.APIServerAddress,
+			HostPort:      cfg.LoadBalancer.StatsPort,
+			ContainerPort: loadbalancer.StatsPort,
+		})...)
+	}
+
+	// finally, specify the image to run, and the command if the load
+	// balancer implementation needs one
+	impl, err := loadbalancer.ForType(cfg.LoadBalancer.Type)
+	if err != nil {
+		return nil, err
+	}
+	image := cfg.LoadBalancer.Image
+	if image == "" {
+		image = impl.Image()
+	}
+	args = append(args, image)
+	return append(args, impl.Command()...), nil
 }
 
 func getProxyEnv(cfg *config.Cluster) (map[string]string, error) {
@@ -17,10 +17,12 @@ limitations under the License.
 package cluster
 
 import (
+	"os"
 	"sort"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	internalcontext "sigs.k8s.io/kind/pkg/cluster/internal/context"
@@ -137,12 +139,61 @@ func (p *Provider) ListInternalNodes(name string) ([]nodes.Node, error) {
 }
 
 // CollectLogs will populate dir with cluster logs and other debug files
-func (p *Provider) CollectLogs(name, dir string) error {
+func (p *Provider) CollectLogs(name, dir string, options ...CollectLogsOption) error {
+	n, opts, err := p.collectLogsNodes(name, options)
+	if err != nil {
+		return err
+	}
+	return internallogs.Collect(p.logger, n, dir, opts)
+}
+
+// CollectLogsArchive is like CollectLogs, but writes the logs to a
+// gzip compressed tar archive at archivePath
+func (p *Provider) CollectLogsArchive(name, archivePath string, options ...CollectLogsOption) error {
+	n, opts, err := p.collectLogsNodes(name, options)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create logs archive")
+	}
+	err = internallogs.CollectArchive(p.logger, n, f, opts)
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "failed to write logs archive")
+	}
+	return err
+}
+
+// StreamLogs streams the journal of the cluster nodes concurrently, calling
+// handle with the node name for each line. Calls to handle are serialized.
+func (p *Provider) StreamLogs(name string, handle func(node, line string), options ...StreamLogsOption) error {
+	opts := &internallogs.StreamOptions{}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return err
+		}
+	}
+	n, err := p.ListInternalNodes(name)
+	if err != nil {
+		return err
+	}
+	return internallogs.Stream(n, opts, handle)
+}
+
+// collectLogsNodes returns the nodes and options to collect logs with
+func (p *Provider) collectLogsNodes(name string, options []CollectLogsOption) ([]nodes.Node, *internallogs.Options, error) {
+	opts := &internallogs.Options{}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return nil, nil, err
+		}
+	}
 	// TODO: should use ListNodes and Collect should handle nodes differently
 	// based on role ...
 	n, err := p.ListInternalNodes(name)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return internallogs.Collect(p.logger, n, dir)
+	return n, opts, nil
 }
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
+)
+
+// StreamLogsOption is a Provider.StreamLogs option
+type StreamLogsOption interface {
+	apply(*internallogs.StreamOptions) error
+}
+
+type streamLogsOptionAdapter func(*internallogs.StreamOptions) error
+
+func (c streamLogsOptionAdapter) apply(o *internallogs.StreamOptions) error {
+	return c(o)
+}
+
+// StreamLogsWithNodes limits the streamed logs to the nodes with these
+// names, by default all nodes are streamed
+func StreamLogsWithNodes(names ...string) StreamLogsOption {
+	return streamLogsOptionAdapter(func(o *internallogs.StreamOptions) error {
+		o.Nodes = append(o.Nodes, names...)
+		return nil
+	})
+}
+
+// StreamLogsWithUnits limits the streamed logs to the journal of these
+// systemd units, eg kubelet, by default the full journal is streamed
+func StreamLogsWithUnits(units ...string) StreamLogsOption {
+	return streamLogsOptionAdapter(func(o *internallogs.StreamOptions) error {
+		o.Units = append(o.Units, units...)
+		return nil
+	})
+}
+
+// StreamLogsFollow configures if the logs are followed, continuing to stream
+// new entries until interrupted
+func StreamLogsFollow(follow bool) StreamLogsOption {
+	return streamLogsOptionAdapter(func(o *internallogs.StreamOptions) error {
+		o.Follow = follow
+		return nil
+	})
+}
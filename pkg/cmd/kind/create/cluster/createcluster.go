@@ -36,6 +36,7 @@ type flagpole struct {
 	Config     string
 	ImageName  string
 	Retain     bool
+	Resume     bool
 	Wait       time.Duration
 	Kubeconfig string
 }
@@ -56,6 +57,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
 	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
 	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain nodes for debugging when cluster creation fails")
+	cmd.Flags().BoolVar(&flags.Resume, "resume", false, "resume creating a cluster retained with --retain after a failure, skipping completed steps")
 	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "Wait for control plane node to be ready (default 0s)")
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
 	return cmd
@@ -71,9 +73,12 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	if err != nil {
 		return err
 	}
-	if len(n) != 0 {
+	if len(n) != 0 && !flags.Resume {
 		return fmt.Errorf("node(s) already exist for a cluster with the name %q", flags.Name)
 	}
+	if len(n) == 0 && flags.Resume {
+		return fmt.Errorf("no node(s) exist for a cluster with the name %q, cannot resume", flags.Name)
+	}
 
 	// handle config flag, we might need to read from stdin
 	withConfig, err := configOption(flags.Config, streams.In)
@@ -82,12 +87,17 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	}
 
 	// create the cluster
-	logger.V(0).Infof("Creating cluster %q ...\n", flags.Name)
+	if flags.Resume {
+		logger.V(0).Infof("Resuming creating cluster %q ...\n", flags.Name)
+	} else {
+		logger.V(0).Infof("Creating cluster %q ...\n", flags.Name)
+	}
 	if err = provider.Create(
 		flags.Name,
 		withConfig,
 		cluster.CreateWithNodeImage(flags.ImageName),
 		cluster.CreateWithRetain(flags.Retain),
+		cluster.CreateWithResume(flags.Resume),
 		cluster.CreateWithWaitForReady(flags.Wait),
 		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
 		cluster.CreateWithDisplayUsage(true),
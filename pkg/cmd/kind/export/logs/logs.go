@@ -19,17 +19,27 @@ package logs
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/internal/diagnose"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 type flagpole struct {
-	Name string
+	Name       string
+	Archive    string
+	Since      time.Duration
+	Nodes      []string
+	Components []string
+	MaxSize    string
+	Analyze    bool
 }
 
 // NewCommand returns a new cobra.Command for getting the cluster logs
@@ -40,16 +50,34 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		// TODO(bentheelder): more detailed usage
 		Use:   "logs [output-dir]",
 		Short: "exports logs to a tempdir or [output-dir] if specified",
-		Long:  "exports logs to a tempdir or [output-dir] if specified",
+		Long: "exports logs to a tempdir or [output-dir] if specified, " +
+			"or to a gzip compressed tar archive with --archive",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runE(logger, streams, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVar(&flags.Archive, "archive", "", "export the logs to this gzip compressed tar archive instead of a directory")
+	cmd.Flags().DurationVar(&flags.Since, "since", 0, "only export logs written in this long, eg 1h, by default all logs are exported")
+	cmd.Flags().StringSliceVar(&flags.Nodes, "nodes", nil, "only export logs from these nodes, by default all nodes are exported")
+	cmd.Flags().StringSliceVar(
+		&flags.Components, "components", nil,
+		fmt.Sprintf("only export these components, one or more of %s, by default all components are exported", strings.Join(cluster.CollectLogsComponents(), ", ")),
+	)
+	cmd.Flags().StringVar(&flags.MaxSize, "max-size", "", "only export the last max-size bytes of each file, in bytes or with a Ki, Mi or Gi suffix, eg 10Mi, by default files are not limited")
+	cmd.Flags().BoolVar(&flags.Analyze, "analyze", false, "scan the exported logs for known problems and print a report with hints for fixing them")
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	if flags.Archive != "" && len(args) > 0 {
+		return fmt.Errorf("--archive and [output-dir] cannot both be specified")
+	}
+	options, err := collectLogsOptions(flags)
+	if err != nil {
+		return err
+	}
+
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 	)
@@ -63,6 +91,19 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []stri
 		return fmt.Errorf("unknown cluster %q", flags.Name)
 	}
 
+	// collect the logs to the archive
+	if flags.Archive != "" {
+		err := provider.CollectLogsArchive(flags.Name, flags.Archive, options...)
+		if err == nil {
+			logger.V(0).Infof("Exported logs for cluster %q to:", flags.Name)
+			fmt.Fprintln(streams.Out, flags.Archive)
+		}
+		if flags.Analyze {
+			return analyze(logger, streams, diagnose.AnalyzeArchive, flags.Archive, err)
+		}
+		return err
+	}
+
 	// get the optional directory argument, or create a tempdir
 	var dir string
 	if len(args) == 0 {
@@ -76,11 +117,70 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []stri
 	}
 
 	// collect the logs
-	if err := provider.CollectLogs(flags.Name, dir); err != nil {
+	err = provider.CollectLogs(flags.Name, dir, options...)
+	if err == nil {
+		logger.V(0).Infof("Exported logs for cluster %q to:", flags.Name)
+		fmt.Fprintln(streams.Out, dir)
+	}
+	if flags.Analyze {
+		return analyze(logger, streams, diagnose.AnalyzeDir, dir, err)
+	}
+	return err
+}
+
+// analyze scans the exported logs at path for known problems with
+// analyzeFn and writes the report to streams.Out
+//
+// The logs are analyzed even if collecting them failed with collectErr, as
+// whatever was collected may explain the failure, collectErr is returned
+func analyze(logger log.Logger, streams cmd.IOStreams, analyzeFn func(string) ([]diagnose.Finding, error), path string, collectErr error) error {
+	findings, err := analyzeFn(path)
+	if err != nil {
+		err = fmt.Errorf("failed to analyze exported logs: %v", err)
+		if collectErr != nil {
+			logger.Error(err.Error())
+			return collectErr
+		}
 		return err
 	}
+	fmt.Fprintln(streams.Out)
+	diagnose.WriteReport(streams.Out, findings)
+	return collectErr
+}
+
+// collectLogsOptions converts the filter flags to cluster.CollectLogsOption
+func collectLogsOptions(flags *flagpole) ([]cluster.CollectLogsOption, error) {
+	options := []cluster.CollectLogsOption{
+		cluster.CollectLogsSince(flags.Since),
+		cluster.CollectLogsWithNodes(flags.Nodes...),
+		cluster.CollectLogsWithComponents(flags.Components...),
+	}
+	if flags.MaxSize != "" {
+		maxSize, err := parseSize(flags.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-size %q: %v", flags.MaxSize, err)
+		}
+		options = append(options, cluster.CollectLogsMaxSize(maxSize))
+	}
+	return options, nil
+}
 
-	logger.V(0).Infof("Exported logs for cluster %q to:", flags.Name)
-	fmt.Fprintln(streams.Out, dir)
-	return nil
+// parseSize parses a positive number of bytes, optionally with a Ki, Mi or Gi
+// suffix
+func parseSize(s string) (int64, error) {
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30} {
+		if strings.HasSuffix(s, suffix) {
+			s, multiplier = strings.TrimSuffix(s, suffix), m
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number of bytes, optionally with a Ki, Mi or Gi suffix")
+	}
+	if n <= 0 || n > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("size must be positive and fit in 64 bits")
+	}
+	return n * multiplier, nil
 }
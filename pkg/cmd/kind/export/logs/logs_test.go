@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/internal/diagnose"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Size        string
+		Expected    int64
+		ExpectError bool
+	}{
+		{Size: "512", Expected: 512},
+		{Size: "64Ki", Expected: 64 << 10},
+		{Size: "10Mi", Expected: 10 << 20},
+		{Size: "2Gi", Expected: 2 << 30},
+		{Size: "0", ExpectError: true},
+		{Size: "-1Ki", ExpectError: true},
+		{Size: "10M", ExpectError: true},
+		{Size: "Mi", ExpectError: true},
+		{Size: "1.5Mi", ExpectError: true},
+		{Size: "9223372036854775807Ki", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Size, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseSize(tc.Size)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if result != tc.Expected {
+				t.Errorf("expected %d but got %d", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+	collectErr := errors.New("failed to collect kubelet.log")
+	analyzeErr := errors.New("not a gzip archive")
+	cases := []struct {
+		Name          string
+		CollectErr    error
+		AnalyzeErr    error
+		ExpectedErr   error
+		ExpectsReport bool
+	}{
+		{
+			Name:          "collected",
+			ExpectsReport: true,
+		},
+		{
+			Name:          "collection failed",
+			CollectErr:    collectErr,
+			ExpectedErr:   collectErr,
+			ExpectsReport: true,
+		},
+		{
+			Name:        "collection and analysis failed",
+			CollectErr:  collectErr,
+			AnalyzeErr:  analyzeErr,
+			ExpectedErr: collectErr,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			analyzed := false
+			analyzeFn := func(string) ([]diagnose.Finding, error) {
+				analyzed = true
+				return nil, tc.AnalyzeErr
+			}
+			err := analyze(log.NoopLogger{}, cmd.IOStreams{Out: &out}, analyzeFn, "logs", tc.CollectErr)
+			if err != tc.ExpectedErr {
+				t.Errorf("expected error %v but got %v", tc.ExpectedErr, err)
+			}
+			if !analyzed {
+				t.Errorf("expected the logs to be analyzed")
+			}
+			if reported := strings.Contains(out.String(), "No known problems"); reported != tc.ExpectsReport {
+				t.Errorf("expected report %v but got %q", tc.ExpectsReport, out.String())
+			}
+		})
+	}
+}
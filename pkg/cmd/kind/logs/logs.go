@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs implements the `logs` command
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/env"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+type flagpole struct {
+	Name   string
+	Nodes  []string
+	Units  []string
+	Follow bool
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for streaming the node logs
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "logs",
+		Short: "streams the journal of the cluster nodes",
+		Long: "streams the journal of all of the cluster nodes at once, " +
+			"prefixing each line with the node name",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringSliceVar(&flags.Nodes, "nodes", nil, "only stream these nodes, by default all nodes are streamed")
+	cmd.Flags().StringSliceVar(&flags.Units, "units", nil, "only stream the journal of these systemd units, eg kubelet,containerd, by default the full journal is streamed")
+	cmd.Flags().BoolVarP(&flags.Follow, "follow", "f", false, "keep streaming new journal entries until interrupted")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", "", "if set to \"json\", write each line as a JSON object instead of prefixing it")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if flags.Output != "" && flags.Output != "json" {
+		return fmt.Errorf("invalid --output value %q, the only supported value is \"json\"", flags.Output)
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+	)
+
+	// Check if the cluster has any running nodes
+	n, err := provider.ListInternalNodes(flags.Name)
+	if err != nil {
+		return err
+	}
+	if len(n) == 0 {
+		return fmt.Errorf("unknown cluster %q", flags.Name)
+	}
+	names := []string{}
+	for _, node := range n {
+		names = append(names, node.String())
+	}
+
+	var handle func(node, line string)
+	if flags.Output == "json" {
+		handle = jsonLineWriter(streams.Out)
+	} else {
+		handle = prefixedLineWriter(streams.Out, names, env.IsSmartTerminal(streams.Out))
+	}
+	return provider.StreamLogs(
+		flags.Name,
+		handle,
+		cluster.StreamLogsWithNodes(flags.Nodes...),
+		cluster.StreamLogsWithUnits(flags.Units...),
+		cluster.StreamLogsFollow(flags.Follow),
+	)
+}
+
+// nodeColors are the ANSI colors node name prefixes cycle through
+var nodeColors = []string{
+	"36", // cyan
+	"33", // yellow
+	"32", // green
+	"35", // magenta
+	"34", // blue
+	"96", // bright cyan
+	"93", // bright yellow
+	"92", // bright green
+	"95", // bright magenta
+	"94", // bright blue
+}
+
+// prefixedLineWriter returns a handler writing each line to w prefixed with
+// the node name, aligned and, if color is set, in a color unique to the node
+func prefixedLineWriter(w io.Writer, nodeNames []string, color bool) func(node, line string) {
+	// assign colors in name order, so they are stable between runs
+	sorted := append([]string{}, nodeNames...)
+	sort.Strings(sorted)
+	width := 0
+	prefixes := map[string]string{}
+	for _, name := range sorted {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for i, name := range sorted {
+		prefix := name + strings.Repeat(" ", width-len(name)) + " |"
+		if color {
+			prefix = "\x1b[" + nodeColors[i%len(nodeColors)] + "m" + prefix + "\x1b[0m"
+		}
+		prefixes[name] = prefix
+	}
+	return func(node, line string) {
+		prefix, ok := prefixes[node]
+		if !ok {
+			prefix = node + " |"
+		}
+		fmt.Fprintln(w, prefix, line)
+	}
+}
+
+// jsonLine is a line of a node's journal in --output=json
+type jsonLine struct {
+	Node string `json:"node"`
+	Line string `json:"line"`
+}
+
+// jsonLineWriter returns a handler writing each line to w as a JSON object
+func jsonLineWriter(w io.Writer) func(node, line string) {
+	encoder := json.NewEncoder(w)
+	return func(node, line string) {
+		// this cannot fail, jsonLine only contains strings
+		_ = encoder.Encode(jsonLine{Node: node, Line: line})
+	}
+}
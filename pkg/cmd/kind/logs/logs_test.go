@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLineWriters(t *testing.T) {
+	t.Parallel()
+	nodes := []string{"kind-worker", "kind-control-plane"}
+	cases := []struct {
+		Name     string
+		Handle   func(*bytes.Buffer) func(node, line string)
+		Expected string
+	}{
+		{
+			Name: "prefixed",
+			Handle: func(b *bytes.Buffer) func(node, line string) {
+				return prefixedLineWriter(b, nodes, false)
+			},
+			Expected: "kind-worker        | started kubelet\n" +
+				"kind-control-plane | started kubelet\n",
+		},
+		{
+			Name: "prefixed in color",
+			Handle: func(b *bytes.Buffer) func(node, line string) {
+				return prefixedLineWriter(b, nodes, true)
+			},
+			Expected: "\x1b[33mkind-worker        |\x1b[0m started kubelet\n" +
+				"\x1b[36mkind-control-plane |\x1b[0m started kubelet\n",
+		},
+		{
+			Name: "json",
+			Handle: func(b *bytes.Buffer) func(node, line string) {
+				return jsonLineWriter(b)
+			},
+			Expected: `{"node":"kind-worker","line":"started kubelet"}` + "\n" +
+				`{"node":"kind-control-plane","line":"started kubelet"}` + "\n",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			var b bytes.Buffer
+			handle := tc.Handle(&b)
+			handle("kind-worker", "started kubelet")
+			handle("kind-control-plane", "started kubelet")
+			if b.String() != tc.Expected {
+				t.Errorf("Output did not match!")
+				t.Errorf("Expected: %q", tc.Expected)
+				t.Errorf("But got: %q", b.String())
+			}
+		})
+	}
+}
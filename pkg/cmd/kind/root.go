@@ -31,14 +31,17 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd/kind/export"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get"
 	"sigs.k8s.io/kind/pkg/cmd/kind/load"
+	"sigs.k8s.io/kind/pkg/cmd/kind/logs"
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 type flagpole struct {
-	LogLevel  string
-	Verbosity int32
-	Quiet     bool
+	LogLevel     string
+	Verbosity    int32
+	Quiet        bool
+	OutputEvents string
 }
 
 // NewCommand returns a new cobra.Command implementing the root command for kind
@@ -77,6 +80,12 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		false,
 		"silence all stderr output",
 	)
+	cmd.PersistentFlags().StringVar(
+		&flags.OutputEvents,
+		"output-events",
+		"",
+		"if set to \"json\", write progress events as JSON lines to stderr instead of human readable output",
+	)
 	// add all top level subcommands
 	cmd.AddCommand(build.NewCommand(logger, streams))
 	cmd.AddCommand(completion.NewCommand(logger, streams))
@@ -86,6 +95,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd.AddCommand(get.NewCommand(logger, streams))
 	cmd.AddCommand(version.NewCommand(logger, streams))
 	cmd.AddCommand(load.NewCommand(logger, streams))
+	cmd.AddCommand(logs.NewCommand(logger, streams))
 	return cmd
 }
 
@@ -101,6 +111,11 @@ func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 			flags.Verbosity = 2147483647
 		}
 	}
+	// NOTE: the logger is swapped for --output-events in app.Run, we only
+	// validate the value here
+	if flags.OutputEvents != "" && flags.OutputEvents != "json" {
+		return errors.Errorf("invalid --output-events value %q, the only supported value is \"json\"", flags.OutputEvents)
+	}
 	// normal logger setup
 	if flags.Quiet {
 		// NOTE: if we are coming from app.Run handling this flag is
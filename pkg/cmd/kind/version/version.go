@@ -19,46 +19,31 @@ package version
 
 import (
 	"fmt"
-	"runtime"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/version"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // Version returns the kind CLI Semantic Version
 func Version() string {
-	v := VersionCore
-	// add pre-release version info if we have it
-	if VersionPreRelease != "" {
-		v += "-" + VersionPreRelease
-		// if commit was set, add the + <build>
-		// we only do this for pre-release versions
-		if GitCommit != "" {
-			// NOTE: use 14 character short hash, like Kubernetes
-			v += "+" + truncate(GitCommit, 14)
-		}
-	}
-	return v
+	return version.Version()
 }
 
 // DisplayVersion is Version() display formatted, this is what the version
 // subcommand prints
 func DisplayVersion() string {
-	return "kind v" + Version() + " " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH
+	return version.DisplayVersion()
 }
 
 // VersionCore is the core portion of the kind CLI version per Semantic Versioning 2.0.0
-const VersionCore = "0.7.0"
+const VersionCore = version.VersionCore
 
 // VersionPreRelease is the pre-release portion of the kind CLI version per
 // Semantic Versioning 2.0.0
-const VersionPreRelease = "alpha"
-
-// GitCommit is the commit used to build the kind binary, if available.
-// It is injected at build time.
-var GitCommit = ""
+const VersionPreRelease = version.VersionPreRelease
 
 // NewCommand returns a new cobra.Command for version
 func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
@@ -80,10 +65,3 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	}
 	return cmd
 }
-
-func truncate(s string, maxLen int) string {
-	if len(s) < maxLen {
-		return s
-	}
-	return s[:maxLen]
-}
@@ -45,3 +45,9 @@ func ColorEnabled(logger log.Logger) bool {
 	v, ok := logger.(maybeColorer)
 	return ok && v.ColorEnabled()
 }
+
+// NewJSONLogger returns a logger that writes all messages and structured
+// progress events (see log.EventSink) to w as JSON lines
+func NewJSONLogger(w io.Writer) log.Logger {
+	return cli.NewJSONLogger(w, 0)
+}
@@ -108,6 +108,8 @@ func RunWithStdoutReader(cmd Cmd, readerFunc func(io.Reader) error) error {
 			return readerFunc(pr)
 		},
 		func() error {
+			// close the pipe once cmd exits, so readerFunc sees EOF
+			defer pw.Close()
 			return cmd.Run()
 		},
 	})
@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	v1alpha4 "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+// ConvertTov1alpha4 converts a cluster at the internal API version to a
+// v1alpha4 cluster, the reverse of Convertv1alpha4
+func ConvertTov1alpha4(in *Cluster) *v1alpha4.Cluster {
+	in = in.DeepCopy() // deep copy first to avoid touching the original
+	out := &v1alpha4.Cluster{
+		TypeMeta: v1alpha4.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: "kind.x-k8s.io/v1alpha4",
+		},
+		Nodes:                           make([]v1alpha4.Node, len(in.Nodes)),
+		FeatureGates:                    in.FeatureGates,
+		RuntimeConfig:                   in.RuntimeConfig,
+		KubeadmConfigPatches:            in.KubeadmConfigPatches,
+		KubeadmConfigPatchesJSON6902:    make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
+		KubeadmConfigSelectedPatches:    make([]v1alpha4.SelectedPatch, len(in.KubeadmConfigSelectedPatches)),
+		ContainerdConfigPatches:         in.ContainerdConfigPatches,
+		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		Manifests:                       make([]v1alpha4.Manifest, len(in.Manifests)),
+	}
+
+	for i := range in.Nodes {
+		convertTov1alpha4Node(&in.Nodes[i], &out.Nodes[i])
+	}
+
+	convertTov1alpha4Networking(&in.Networking, &out.Networking)
+
+	convertTov1alpha4LoadBalancer(&in.LoadBalancer, &out.LoadBalancer)
+
+	convertTov1alpha4Storage(&in.Storage, &out.Storage)
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertTov1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+
+	for i := range in.KubeadmConfigSelectedPatches {
+		convertTov1alpha4SelectedPatch(&in.KubeadmConfigSelectedPatches[i], &out.KubeadmConfigSelectedPatches[i])
+	}
+
+	for i := range in.Manifests {
+		convertTov1alpha4Manifest(&in.Manifests[i], &out.Manifests[i])
+	}
+
+	convertTov1alpha4Hooks(&in.Hooks, &out.Hooks)
+
+	out.Files = convertTov1alpha4FileList(in.Files)
+
+	return out
+}
+
+func convertTov1alpha4Node(in *Node, out *v1alpha4.Node) {
+	out.Role = v1alpha4.NodeRole(in.Role)
+	out.Image = in.Image
+
+	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.ContainerdConfigPatches = in.ContainerdConfigPatches
+	out.ContainerdConfigPatchesJSON6902 = in.ContainerdConfigPatchesJSON6902
+	out.ExtraMounts = make([]v1alpha4.Mount, len(in.ExtraMounts))
+	out.ExtraPortMappings = make([]v1alpha4.PortMapping, len(in.ExtraPortMappings))
+	out.KubeadmConfigPatchesJSON6902 = make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
+
+	for i := range in.ExtraMounts {
+		convertTov1alpha4Mount(&in.ExtraMounts[i], &out.ExtraMounts[i])
+	}
+
+	for i := range in.ExtraPortMappings {
+		convertTov1alpha4PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
+	}
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertTov1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+
+	if in.Ingress != nil {
+		out.Ingress = &v1alpha4.Ingress{
+			HTTPPort:      in.Ingress.HTTPPort,
+			HTTPSPort:     in.Ingress.HTTPSPort,
+			ListenAddress: in.Ingress.ListenAddress,
+		}
+	}
+
+	out.Files = convertTov1alpha4FileList(in.Files)
+}
+
+func convertTov1alpha4PatchJSON6902(in *PatchJSON6902, out *v1alpha4.PatchJSON6902) {
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Kind = in.Kind
+	out.Patch = in.Patch
+	if in.Nodes != nil {
+		out.Nodes = &v1alpha4.NodeSelector{}
+		convertTov1alpha4NodeSelector(in.Nodes, out.Nodes)
+	}
+}
+
+func convertTov1alpha4SelectedPatch(in *SelectedPatch, out *v1alpha4.SelectedPatch) {
+	convertTov1alpha4NodeSelector(&in.Nodes, &out.Nodes)
+	out.Patch = in.Patch
+}
+
+func convertTov1alpha4NodeSelector(in *NodeSelector, out *v1alpha4.NodeSelector) {
+	out.Roles = make([]v1alpha4.NodeRole, len(in.Roles))
+	for i, role := range in.Roles {
+		out.Roles[i] = v1alpha4.NodeRole(role)
+	}
+	out.Indexes = in.Indexes
+	out.Names = in.Names
+}
+
+func convertTov1alpha4Networking(in *Networking, out *v1alpha4.Networking) {
+	out.IPFamily = v1alpha4.ClusterIPFamily(in.IPFamily)
+	out.APIServerPort = in.APIServerPort
+	out.APIServerAddress = in.APIServerAddress
+	out.PodSubnet = in.PodSubnet
+	out.ServiceSubnet = in.ServiceSubnet
+	out.DisableDefaultCNI = in.DisableDefaultCNI
+	out.EnableNetworkPolicy = in.EnableNetworkPolicy
+	out.EnableLoadBalancer = in.EnableLoadBalancer
+	out.LoadBalancerAddressPool = in.LoadBalancerAddressPool
+	out.KubeProxyMode = v1alpha4.ProxyMode(in.KubeProxyMode)
+}
+
+func convertTov1alpha4LoadBalancer(in *LoadBalancer, out *v1alpha4.LoadBalancer) {
+	out.Type = v1alpha4.LoadBalancerType(in.Type)
+	out.Image = in.Image
+	out.ConfigTemplate = in.ConfigTemplate
+	out.ConnectTimeout = in.ConnectTimeout
+	out.ClientTimeout = in.ClientTimeout
+	out.ServerTimeout = in.ServerTimeout
+	out.StatsPort = in.StatsPort
+}
+
+func convertTov1alpha4Storage(in *Storage, out *v1alpha4.Storage) {
+	out.DisableDefaultStorageClass = in.DisableDefaultStorageClass
+	out.NonDefaultStorageClass = in.NonDefaultStorageClass
+	if in.Manifest != nil {
+		out.Manifest = &v1alpha4.Manifest{}
+		convertTov1alpha4Manifest(in.Manifest, out.Manifest)
+	}
+	out.LocalPath = in.LocalPath
+	out.PersistentHostPath = in.PersistentHostPath
+}
+
+func convertTov1alpha4Mount(in *Mount, out *v1alpha4.Mount) {
+	out.ContainerPath = in.ContainerPath
+	out.HostPath = in.HostPath
+	out.Readonly = in.Readonly
+	out.SelinuxRelabel = in.SelinuxRelabel
+	out.Propagation = v1alpha4.MountPropagation(in.Propagation)
+}
+
+func convertTov1alpha4PortMapping(in *PortMapping, out *v1alpha4.PortMapping) {
+	out.ContainerPort = in.ContainerPort
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Protocol = v1alpha4.PortMappingProtocol(in.Protocol)
+}
+
+func convertTov1alpha4Manifest(in *Manifest, out *v1alpha4.Manifest) {
+	out.Path = in.Path
+	out.Content = in.Content
+	out.WaitForRollout = in.WaitForRollout
+}
+
+func convertTov1alpha4Hooks(in *Hooks, out *v1alpha4.Hooks) {
+	out.PostProvision = convertTov1alpha4HookList(in.PostProvision)
+	out.PreKubeadmInit = convertTov1alpha4HookList(in.PreKubeadmInit)
+	out.PostJoin = convertTov1alpha4HookList(in.PostJoin)
+	out.PostCreate = convertTov1alpha4HookList(in.PostCreate)
+}
+
+func convertTov1alpha4HookList(in []Hook) []v1alpha4.Hook {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha4.Hook, len(in))
+	for i := range in {
+		convertTov1alpha4Hook(&in[i], &out[i])
+	}
+	return out
+}
+
+func convertTov1alpha4Hook(in *Hook, out *v1alpha4.Hook) {
+	out.Name = in.Name
+	out.Command = in.Command
+	out.RunOnHost = in.RunOnHost
+	out.Roles = make([]v1alpha4.NodeRole, len(in.Roles))
+	for i := range in.Roles {
+		out.Roles[i] = v1alpha4.NodeRole(in.Roles[i])
+	}
+}
+
+func convertTov1alpha4FileList(in []File) []v1alpha4.File {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha4.File, len(in))
+	for i := range in {
+		convertTov1alpha4File(&in[i], &out[i])
+	}
+	return out
+}
+
+func convertTov1alpha4File(in *File, out *v1alpha4.File) {
+	out.Path = in.Path
+	out.Content = in.Content
+	out.HostPath = in.HostPath
+	out.Permissions = in.Permissions
+	out.Owner = in.Owner
+}
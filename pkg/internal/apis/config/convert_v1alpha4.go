@@ -25,10 +25,14 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 	in = in.DeepCopy() // deep copy first to avoid touching the original
 	out := &Cluster{
 		Nodes:                           make([]Node, len(in.Nodes)),
+		FeatureGates:                    in.FeatureGates,
+		RuntimeConfig:                   in.RuntimeConfig,
 		KubeadmConfigPatches:            in.KubeadmConfigPatches,
 		KubeadmConfigPatchesJSON6902:    make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
+		KubeadmConfigSelectedPatches:    make([]SelectedPatch, len(in.KubeadmConfigSelectedPatches)),
 		ContainerdConfigPatches:         in.ContainerdConfigPatches,
 		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		Manifests:                       make([]Manifest, len(in.Manifests)),
 	}
 
 	for i := range in.Nodes {
@@ -37,10 +41,26 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 
 	convertv1alpha4Networking(&in.Networking, &out.Networking)
 
+	convertv1alpha4LoadBalancer(&in.LoadBalancer, &out.LoadBalancer)
+
+	convertv1alpha4Storage(&in.Storage, &out.Storage)
+
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
 
+	for i := range in.KubeadmConfigSelectedPatches {
+		convertv1alpha4SelectedPatch(&in.KubeadmConfigSelectedPatches[i], &out.KubeadmConfigSelectedPatches[i])
+	}
+
+	for i := range in.Manifests {
+		convertv1alpha4Manifest(&in.Manifests[i], &out.Manifests[i])
+	}
+
+	convertv1alpha4Hooks(&in.Hooks, &out.Hooks)
+
+	out.Files = convertv1alpha4FileList(in.Files)
+
 	return out
 }
 
@@ -49,6 +69,8 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	out.Image = in.Image
 
 	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.ContainerdConfigPatches = in.ContainerdConfigPatches
+	out.ContainerdConfigPatchesJSON6902 = in.ContainerdConfigPatchesJSON6902
 	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
 	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
 	out.KubeadmConfigPatchesJSON6902 = make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
@@ -64,6 +86,16 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
+
+	if in.Ingress != nil {
+		out.Ingress = &Ingress{
+			HTTPPort:      in.Ingress.HTTPPort,
+			HTTPSPort:     in.Ingress.HTTPSPort,
+			ListenAddress: in.Ingress.ListenAddress,
+		}
+	}
+
+	out.Files = convertv1alpha4FileList(in.Files)
 }
 
 func convertv1alpha4PatchJSON6902(in *v1alpha4.PatchJSON6902, out *PatchJSON6902) {
@@ -71,6 +103,24 @@ func convertv1alpha4PatchJSON6902(in *v1alpha4.PatchJSON6902, out *PatchJSON6902
 	out.Version = in.Version
 	out.Kind = in.Kind
 	out.Patch = in.Patch
+	if in.Nodes != nil {
+		out.Nodes = &NodeSelector{}
+		convertv1alpha4NodeSelector(in.Nodes, out.Nodes)
+	}
+}
+
+func convertv1alpha4SelectedPatch(in *v1alpha4.SelectedPatch, out *SelectedPatch) {
+	convertv1alpha4NodeSelector(&in.Nodes, &out.Nodes)
+	out.Patch = in.Patch
+}
+
+func convertv1alpha4NodeSelector(in *v1alpha4.NodeSelector, out *NodeSelector) {
+	out.Roles = make([]NodeRole, len(in.Roles))
+	for i, role := range in.Roles {
+		out.Roles[i] = NodeRole(role)
+	}
+	out.Indexes = in.Indexes
+	out.Names = in.Names
 }
 
 func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
@@ -80,6 +130,31 @@ func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
 	out.PodSubnet = in.PodSubnet
 	out.ServiceSubnet = in.ServiceSubnet
 	out.DisableDefaultCNI = in.DisableDefaultCNI
+	out.EnableNetworkPolicy = in.EnableNetworkPolicy
+	out.EnableLoadBalancer = in.EnableLoadBalancer
+	out.LoadBalancerAddressPool = in.LoadBalancerAddressPool
+	out.KubeProxyMode = ProxyMode(in.KubeProxyMode)
+}
+
+func convertv1alpha4LoadBalancer(in *v1alpha4.LoadBalancer, out *LoadBalancer) {
+	out.Type = LoadBalancerType(in.Type)
+	out.Image = in.Image
+	out.ConfigTemplate = in.ConfigTemplate
+	out.ConnectTimeout = in.ConnectTimeout
+	out.ClientTimeout = in.ClientTimeout
+	out.ServerTimeout = in.ServerTimeout
+	out.StatsPort = in.StatsPort
+}
+
+func convertv1alpha4Storage(in *v1alpha4.Storage, out *Storage) {
+	out.DisableDefaultStorageClass = in.DisableDefaultStorageClass
+	out.NonDefaultStorageClass = in.NonDefaultStorageClass
+	if in.Manifest != nil {
+		out.Manifest = &Manifest{}
+		convertv1alpha4Manifest(in.Manifest, out.Manifest)
+	}
+	out.LocalPath = in.LocalPath
+	out.PersistentHostPath = in.PersistentHostPath
 }
 
 func convertv1alpha4Mount(in *v1alpha4.Mount, out *Mount) {
@@ -96,3 +171,56 @@ func convertv1alpha4PortMapping(in *v1alpha4.PortMapping, out *PortMapping) {
 	out.ListenAddress = in.ListenAddress
 	out.Protocol = PortMappingProtocol(in.Protocol)
 }
+
+func convertv1alpha4Manifest(in *v1alpha4.Manifest, out *Manifest) {
+	out.Path = in.Path
+	out.Content = in.Content
+	out.WaitForRollout = in.WaitForRollout
+}
+
+func convertv1alpha4Hooks(in *v1alpha4.Hooks, out *Hooks) {
+	out.PostProvision = convertv1alpha4HookList(in.PostProvision)
+	out.PreKubeadmInit = convertv1alpha4HookList(in.PreKubeadmInit)
+	out.PostJoin = convertv1alpha4HookList(in.PostJoin)
+	out.PostCreate = convertv1alpha4HookList(in.PostCreate)
+}
+
+func convertv1alpha4HookList(in []v1alpha4.Hook) []Hook {
+	if in == nil {
+		return nil
+	}
+	out := make([]Hook, len(in))
+	for i := range in {
+		convertv1alpha4Hook(&in[i], &out[i])
+	}
+	return out
+}
+
+func convertv1alpha4Hook(in *v1alpha4.Hook, out *Hook) {
+	out.Name = in.Name
+	out.Command = in.Command
+	out.RunOnHost = in.RunOnHost
+	out.Roles = make([]NodeRole, len(in.Roles))
+	for i := range in.Roles {
+		out.Roles[i] = NodeRole(in.Roles[i])
+	}
+}
+
+func convertv1alpha4FileList(in []v1alpha4.File) []File {
+	if in == nil {
+		return nil
+	}
+	out := make([]File, len(in))
+	for i := range in {
+		convertv1alpha4File(&in[i], &out[i])
+	}
+	return out
+}
+
+func convertv1alpha4File(in *v1alpha4.File, out *File) {
+	out.Path = in.Path
+	out.Content = in.Content
+	out.HostPath = in.HostPath
+	out.Permissions = in.Permissions
+	out.Owner = in.Owner
+}
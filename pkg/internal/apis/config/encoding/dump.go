@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// redacted replaces file and manifest contents and containerd config patches
+// in dumped configs
+const redacted = "<redacted>"
+
+// Dump encodes the internal `kind` Config to v1alpha4 yaml for debugging,
+// eg to record the effective config of a cluster after defaulting
+//
+// File and manifest contents are redacted, as are containerd config patches,
+// which is where registry credentials are usually configured. Everything else
+// is dumped verbatim, including kubeadm config patches and hook commands, so
+// the dump should still be reviewed before it is shared.
+func Dump(cfg *config.Cluster) ([]byte, error) {
+	out := config.ConvertTov1alpha4(cfg)
+	redactFiles(out.Files)
+	out.ContainerdConfigPatches = redactPatches(out.ContainerdConfigPatches)
+	out.ContainerdConfigPatchesJSON6902 = redactPatches(out.ContainerdConfigPatchesJSON6902)
+	for i := range out.Nodes {
+		n := &out.Nodes[i]
+		redactFiles(n.Files)
+		n.ContainerdConfigPatches = redactPatches(n.ContainerdConfigPatches)
+		n.ContainerdConfigPatchesJSON6902 = redactPatches(n.ContainerdConfigPatchesJSON6902)
+	}
+	for i := range out.Manifests {
+		redactManifest(&out.Manifests[i])
+	}
+	if out.Storage.Manifest != nil {
+		redactManifest(out.Storage.Manifest)
+	}
+	return yaml.Marshal(out)
+}
+
+func redactFiles(files []v1alpha4.File) {
+	for i := range files {
+		if files[i].Content != "" {
+			files[i].Content = redacted
+		}
+	}
+}
+
+// redactPatches returns a copy of patches with each patch redacted, the
+// converted config shares the patches with the internal config
+func redactPatches(patches []string) []string {
+	if patches == nil {
+		return nil
+	}
+	out := make([]string, len(patches))
+	for i := range out {
+		out[i] = redacted
+	}
+	return out
+}
+
+func redactManifest(manifest *v1alpha4.Manifest) {
+	if manifest.Content != "" {
+		manifest.Content = redacted
+	}
+}
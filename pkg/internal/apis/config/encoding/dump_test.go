@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("./testdata/v1alpha4/valid-many-fields.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	cfg.Files = []config.File{{Path: "/etc/motd", Content: "hello"}}
+	cfg.Nodes[0].Files = []config.File{{Path: "/etc/token", Content: "secret-token", Permissions: "0600"}}
+	cfg.Manifests = []config.Manifest{{Content: "kind: Secret"}, {Path: "/manifests/app.yaml"}}
+	cfg.Storage.Manifest = &config.Manifest{Content: "kind: StorageClass"}
+	cfg.ContainerdConfigPatches = []string{`[plugins."io.containerd.grpc.v1.cri".registry.configs."registry.example.com".auth]
+  password = "registry-password"`}
+	cfg.Nodes[0].ContainerdConfigPatchesJSON6902 = []string{`[{"op": "add", "path": "/auth", "value": "node-password"}]`}
+	cfg.KubeadmConfigPatches = []string{"kind: ClusterConfiguration"}
+
+	raw, err := Dump(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error dumping config: %v", err)
+	}
+	dumped := string(raw)
+	if !strings.Contains(cfg.ContainerdConfigPatches[0], "registry-password") {
+		t.Errorf("expected dumping not to modify the config")
+	}
+	for _, content := range []string{"hello", "secret-token", "kind: Secret", "kind: StorageClass", "registry-password", "node-password"} {
+		if strings.Contains(dumped, content) {
+			t.Errorf("expected %q to be redacted, got:\n%s", content, dumped)
+		}
+	}
+	for _, field := range []string{"apiVersion: kind.x-k8s.io/v1alpha4", "apiServerAddress:", "extraPortMappings:", "kind: ClusterConfiguration"} {
+		if !strings.Contains(dumped, field) {
+			t.Errorf("expected %q in the v1alpha4 config, got:\n%s", field, dumped)
+		}
+	}
+
+	// apart from the redacted contents, the dump reads back as the same config
+	parsed, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing dumped config: %v", err)
+	}
+	assert.StringEqual(t, redacted, parsed.Nodes[0].Files[0].Content)
+	parsed.Files[0].Content = cfg.Files[0].Content
+	parsed.Nodes[0].Files[0].Content = cfg.Nodes[0].Files[0].Content
+	parsed.Manifests[0].Content = cfg.Manifests[0].Content
+	parsed.Storage.Manifest.Content = cfg.Storage.Manifest.Content
+	parsed.ContainerdConfigPatches = cfg.ContainerdConfigPatches
+	parsed.Nodes[0].ContainerdConfigPatchesJSON6902 = cfg.Nodes[0].ContainerdConfigPatchesJSON6902
+	assert.DeepEqual(t, cfg, parsed)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// IngressReadyLabel is the label set to "true" on nodes with an Ingress,
+// for use in ingress controller node selectors
+const IngressReadyLabel = "ingress-ready"
+
+// PortMappings returns all of the node's port mappings, the ExtraPortMappings
+// followed by the mappings for the node's Ingress if set
+func (n *Node) PortMappings() []PortMapping {
+	mappings := append([]PortMapping{}, n.ExtraPortMappings...)
+	if n.Ingress != nil {
+		mappings = append(mappings,
+			PortMapping{
+				ContainerPort: 80,
+				HostPort:      n.Ingress.HTTPPort,
+				ListenAddress: n.Ingress.ListenAddress,
+			},
+			PortMapping{
+				ContainerPort: 443,
+				HostPort:      n.Ingress.HTTPSPort,
+				ListenAddress: n.Ingress.ListenAddress,
+			},
+		)
+	}
+	return mappings
+}
@@ -29,6 +29,21 @@ type Cluster struct {
 	// Networking contains cluster wide network settings
 	Networking Networking
 
+	// LoadBalancer contains settings for the external load balancer in front
+	// of the API servers of clusters with multiple control-plane nodes
+	LoadBalancer LoadBalancer
+
+	// Storage contains settings for the default storage provisioner and
+	// StorageClass
+	Storage Storage
+
+	// FeatureGates contains a map of Kubernetes feature gates to whether they
+	// are enabled, these are set on every Kubernetes component
+	FeatureGates map[string]bool
+
+	// RuntimeConfig is the API server's --runtime-config
+	RuntimeConfig map[string]string
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/a9cf5c8f3380bb52ebe57b1e2dbdec136d8dd484/contributors/devel/sig-api-machinery/strategic-merge-patch.md
@@ -39,6 +54,11 @@ type Cluster struct {
 	// as patchesJson6902 to `kustomize build`
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902
 
+	// KubeadmConfigSelectedPatches are merge patches like KubeadmConfigPatches,
+	// that are only applied to the generated kubeadm config of the nodes
+	// matching their selector
+	KubeadmConfigSelectedPatches []SelectedPatch
+
 	// ContainerdConfigPatches are applied to every node's containerd config
 	// in the order listed.
 	// These should be toml stringsto be applied as merge patches
@@ -48,6 +68,20 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string
+
+	// Manifests are applied to the cluster in the order listed with
+	// `kubectl apply`, after the default CNI and StorageClass are installed
+	// and all nodes have joined.
+	// These may be used to install CRDs, namespaces, RBAC etc.
+	Manifests []Manifest
+
+	// Hooks are commands run on the host or in the nodes at defined phases
+	// of cluster creation
+	Hooks Hooks
+
+	// Files are written to every node before Kubernetes is set up.
+	// Node-level files are written after the cluster-level files.
+	Files []File
 }
 
 // Node contains settings for a node in the `kind` Cluster.
@@ -74,6 +108,11 @@ type Node struct {
 	// binded to a host Port
 	ExtraPortMappings []PortMapping
 
+	// Ingress marks the node as ready for an ingress controller, forwarding
+	// host ports to the node's ports 80 and 443 and labeling the node with
+	// ingress-ready=true
+	Ingress *Ingress
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/a9cf5c8f3380bb52ebe57b1e2dbdec136d8dd484/contributors/devel/sig-api-machinery/strategic-merge-patch.md
@@ -83,6 +122,22 @@ type Node struct {
 	// KubeadmConfigPatchesJSON6902 are applied to the generated kubeadm config
 	// as patchesJson6902 to `kustomize build`
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902
+
+	// Files are written to the node before Kubernetes is set up, after the
+	// cluster-level files have been written. (See Cluster.Files)
+	Files []File
+
+	// ContainerdConfigPatches are applied to the node's containerd config
+	// in the order listed, after the cluster-level patches have been applied.
+	// (See Cluster.ContainerdConfigPatches)
+	// These should be toml strings to be applied as merge patches
+	ContainerdConfigPatches []string
+
+	// ContainerdConfigPatchesJSON6902 are applied to the node's containerd
+	// config in the order listed, after the cluster-level patches have been
+	// applied. (See Cluster.ContainerdConfigPatchesJSON6902)
+	// These should be YAML or JSON formatting RFC 6902 JSON patches
+	ContainerdConfigPatchesJSON6902 []string
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -100,7 +155,8 @@ const (
 
 // Networking contains cluster wide network settings
 type Networking struct {
-	// IPFamily is the network cluster model, currently it can be ipv4 or ipv6
+	// IPFamily is the network cluster model, currently it can be ipv4, ipv6
+	// or dual (dual-stack IPv4 and IPv6)
 	IPFamily ClusterIPFamily
 	// APIServerPort is the listen port on the host for the Kubernetes API Server
 	// Defaults to a random port on the host
@@ -112,13 +168,84 @@ type Networking struct {
 	APIServerAddress string
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
+	// For dual stack clusters this is an IPv4 and an IPv6 CIDR, comma separated
 	PodSubnet string
 	// ServiceSubnet is the CIDR used for services VIPs
 	// kind will select a default if unspecified
+	// For dual stack clusters this is an IPv4 and an IPv6 CIDR, comma separated
 	ServiceSubnet string
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool
+	// If EnableNetworkPolicy is true, the default CNI will enforce
+	// NetworkPolicies. This requires the default CNI.
+	EnableNetworkPolicy bool
+	// If EnableLoadBalancer is true, kind will deploy a controller assigning
+	// IPs from the network the nodes share to Services of type LoadBalancer
+	EnableLoadBalancer bool
+	// LoadBalancerAddressPool is the CIDR LoadBalancer IPs are assigned from,
+	// it should be an unused range of the network the nodes share
+	// Defaults to a range at the end of the node network
+	LoadBalancerAddressPool string
+	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs
+	// mode, or if kube-proxy should not be deployed at all (none)
+	KubeProxyMode ProxyMode
+}
+
+// LoadBalancer contains settings for the external control-plane load balancer
+type LoadBalancer struct {
+	// Type is the load balancer implementation
+	Type LoadBalancerType
+	// Image is the load balancer image to run, if unset an image for the
+	// Type is used
+	Image string
+	// ConfigTemplate replaces the default config template of the Type
+	ConfigTemplate string
+	// ConnectTimeout is the maximum time to wait for a connection to an API
+	// server to be established, as a duration string eg "5s"
+	ConnectTimeout string
+	// ClientTimeout is the maximum time a client connection may be idle
+	ClientTimeout string
+	// ServerTimeout is the maximum time an API server connection may be idle
+	ServerTimeout string
+	// StatsPort is the host port the load balancer statistics page is
+	// published on, if zero it is not published
+	StatsPort int32
+}
+
+// LoadBalancerType is the load balancer implementation
+type LoadBalancerType string
+
+const (
+	// HAProxyLoadBalancer sets LoadBalancerType to haproxy
+	HAProxyLoadBalancer LoadBalancerType = "haproxy"
+	// EnvoyLoadBalancer sets LoadBalancerType to envoy
+	EnvoyLoadBalancer LoadBalancerType = "envoy"
+	// NginxLoadBalancer sets LoadBalancerType to nginx
+	NginxLoadBalancer LoadBalancerType = "nginx"
+)
+
+// DefaultStorageLocalPath is the default Storage LocalPath
+const DefaultStorageLocalPath = "/var/local-path-provisioner"
+
+// Storage contains settings for the default storage provisioner and
+// StorageClass installed by kind
+type Storage struct {
+	// DisableDefaultStorageClass disables installing the default storage
+	// provisioner and StorageClass
+	DisableDefaultStorageClass bool
+	// NonDefaultStorageClass installs the default storage provisioner's
+	// StorageClass without marking it as the cluster's default StorageClass
+	NonDefaultStorageClass bool
+	// Manifest replaces the default storage provisioner and StorageClass
+	Manifest *Manifest
+	// LocalPath is the directory on the nodes that the default storage
+	// provisioner creates volumes in
+	LocalPath string
+	// PersistentHostPath is a directory on the host that backs the default
+	// storage provisioner's volumes, volumes are named by PVC in a directory
+	// for the cluster under it
+	PersistentHostPath string
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -129,8 +256,108 @@ const (
 	IPv4Family ClusterIPFamily = "ipv4"
 	// IPv6Family sets ClusterIPFamily to ipv6
 	IPv6Family ClusterIPFamily = "ipv6"
+	// DualStackFamily sets ClusterIPFamily to dual
+	DualStackFamily ClusterIPFamily = "dual"
 )
 
+// ProxyMode defines a proxy mode for kube-proxy
+type ProxyMode string
+
+const (
+	// IPTablesProxyMode sets ProxyMode to iptables
+	IPTablesProxyMode ProxyMode = "iptables"
+	// IPVSProxyMode sets ProxyMode to ipvs
+	IPVSProxyMode ProxyMode = "ipvs"
+	// NoneProxyMode disables kube-proxy, eg for a CNI that replaces it
+	NoneProxyMode ProxyMode = "none"
+)
+
+// Manifest is a Kubernetes manifest to apply to the cluster after creation
+// Exactly one of Path and Content should be set.
+// In yaml this looks like:
+//  path: ./crds/
+//  waitForRollout: true
+// or:
+//  content: |
+//    apiVersion: v1
+//    kind: Namespace
+//    metadata:
+//      name: test
+type Manifest struct {
+	// Path is a path on the host to a manifest file, or a directory
+	// containing manifest files (.yaml, .yml, .json), which are applied in
+	// lexical order. Relative paths are relative to the current working
+	// directory.
+	Path string
+	// Content is an inline manifest
+	Content string
+	// If WaitForRollout is true, kind will wait for any Deployments,
+	// DaemonSets and StatefulSets in the manifest to finish rolling out
+	WaitForRollout bool
+}
+
+// Hooks contains the commands to run at each phase of cluster creation
+// The hooks for each phase are run in the order listed.
+type Hooks struct {
+	// PostProvision hooks run after the node containers are created,
+	// before anything else is configured
+	PostProvision []Hook
+	// PreKubeadmInit hooks run after the kubeadm config is written to the
+	// nodes, before kubeadm init
+	PreKubeadmInit []Hook
+	// PostJoin hooks run after all nodes have joined the cluster
+	PostJoin []Hook
+	// PostCreate hooks run once cluster creation is complete, after the
+	// kubeconfig is exported
+	PostCreate []Hook
+}
+
+// Hook is a command to run at some phase of cluster creation
+// In yaml this looks like:
+//  name: update-ca-certificates
+//  command: ["update-ca-certificates"]
+//  roles: ["worker"]
+// or:
+//  command: ["./hack/post-create.sh"]
+//  runOnHost: true
+type Hook struct {
+	// Name is used when reporting progress and errors
+	// Defaults to the command
+	Name string
+	// Command is the command and arguments to run, no shell is used
+	Command []string
+	// If RunOnHost is true, the command runs on the host, with the
+	// KIND_CLUSTER_NAME environment variable set to the cluster name.
+	// Otherwise it runs in each matching node.
+	RunOnHost bool
+	// Roles selects the nodes to run the command in
+	// Defaults to all nodes
+	Roles []NodeRole
+}
+
+// File is a file to write into nodes before Kubernetes is set up
+// Exactly one of Content and HostPath should be set.
+// In yaml this looks like:
+//  path: /etc/kubernetes/audit-policy.yaml
+//  hostPath: ./audit-policy.yaml
+//  permissions: "0600"
+//  owner: root:root
+type File struct {
+	// Path is the absolute path of the file within the node
+	Path string
+	// Content is the content of the file
+	Content string
+	// HostPath is a path to a file on the host to read the content from.
+	// Relative paths are relative to the current working directory.
+	HostPath string
+	// Permissions is the octal file mode to set on the file
+	// Defaults to "0644"
+	Permissions string
+	// Owner is the "user:group" to own the file
+	// Defaults to "root:root"
+	Owner string
+}
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -140,6 +367,28 @@ type PatchJSON6902 struct {
 	Kind    string
 	// Patch should contain the contents of the json patch as a string
 	Patch string
+	// Nodes limits the patch to the matching nodes, if set
+	Nodes *NodeSelector
+}
+
+// SelectedPatch is a merge patch applied only to the nodes matching Nodes
+type SelectedPatch struct {
+	// Nodes selects the nodes to patch
+	Nodes NodeSelector
+	// Patch should contain the contents of the merge patch as a string
+	Patch string
+}
+
+// NodeSelector selects nodes from Cluster.Nodes.
+// A node matches if it matches every field that is set, and a field matches
+// if any of its entries match. An empty selector matches every node.
+type NodeSelector struct {
+	// Roles matches nodes with any of these roles
+	Roles []NodeRole
+	// Indexes matches nodes by their (zero based) index in Cluster.Nodes
+	Indexes []int32
+	// Names matches nodes whose name matches any of these shell patterns
+	Names []string
 }
 
 // Mount specifies a host volume to mount into a container.
@@ -187,6 +436,20 @@ type PortMapping struct {
 	Protocol PortMappingProtocol
 }
 
+// Ingress configures the host ports forwarded to an ingress controller
+// running on a node
+type Ingress struct {
+	// HTTPPort is the host port forwarded to the node's port 80
+	// Defaults to 80
+	HTTPPort int32
+	// HTTPSPort is the host port forwarded to the node's port 443
+	// Defaults to 443
+	HTTPSPort int32
+	// ListenAddress is the host address the ports are bound to
+	// Defaults to all addresses
+	ListenAddress string
+}
+
 // MountPropagation represents an "enum" for mount propagation options,
 // see also Mount.
 type MountPropagation string
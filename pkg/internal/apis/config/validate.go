@@ -18,8 +18,14 @@ package config
 
 import (
 	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/lbtemplate"
 )
 
 // Validate returns a ConfigErrors with an entry for each problem
@@ -36,15 +42,97 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
-	// podSubnet should be a valid CIDR
-	if _, _, err := net.ParseCIDR(c.Networking.PodSubnet); err != nil {
+	// the ip family should be one of the supported families
+	isDualStack := false
+	switch c.Networking.IPFamily {
+	case IPv4Family,
+		IPv6Family:
+	case DualStackFamily:
+		isDualStack = true
+	default:
+		errs = append(errs, errors.Errorf("invalid ipFamily: %q", c.Networking.IPFamily))
+	}
+
+	// podSubnet should be a valid CIDR, or one of each family for dual stack
+	if err := validateSubnets(c.Networking.PodSubnet, isDualStack); err != nil {
 		errs = append(errs, errors.Wrapf(err, "invalid podSubnet"))
 	}
-	// serviceSubnet should be a valid CIDR
-	if _, _, err := net.ParseCIDR(c.Networking.ServiceSubnet); err != nil {
+	// serviceSubnet should be a valid CIDR, or one of each family for dual stack
+	if err := validateSubnets(c.Networking.ServiceSubnet, isDualStack); err != nil {
 		errs = append(errs, errors.Wrapf(err, "invalid serviceSubnet"))
 	}
 
+	// network policy is implemented by the default CNI
+	if c.Networking.EnableNetworkPolicy && c.Networking.DisableDefaultCNI {
+		errs = append(errs, errors.New("enableNetworkPolicy requires the default CNI, but disableDefaultCNI is set"))
+	}
+
+	// the load balancer address pool should be a valid CIDR
+	if c.Networking.LoadBalancerAddressPool != "" {
+		if !c.Networking.EnableLoadBalancer {
+			errs = append(errs, errors.New("loadBalancerAddressPool requires enableLoadBalancer"))
+		}
+		if _, _, err := net.ParseCIDR(c.Networking.LoadBalancerAddressPool); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid loadBalancerAddressPool"))
+		}
+	}
+
+	// the load balancer type should be one of the supported types
+	switch c.LoadBalancer.Type {
+	case HAProxyLoadBalancer,
+		EnvoyLoadBalancer,
+		NginxLoadBalancer:
+	default:
+		errs = append(errs, errors.Errorf("invalid loadBalancer type: %q", c.LoadBalancer.Type))
+	}
+	// load balancer timeouts should be positive durations
+	for name, timeout := range map[string]string{
+		"connectTimeout": c.LoadBalancer.ConnectTimeout,
+		"clientTimeout":  c.LoadBalancer.ClientTimeout,
+		"serverTimeout":  c.LoadBalancer.ServerTimeout,
+	} {
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			errs = append(errs, errors.Errorf("invalid loadBalancer %s: %q", name, timeout))
+		}
+	}
+	// the load balancer stats port is only published if set
+	if c.LoadBalancer.StatsPort != 0 {
+		if err := validatePort(c.LoadBalancer.StatsPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid loadBalancer statsPort"))
+		}
+		if c.LoadBalancer.StatsPort == c.Networking.APIServerPort {
+			errs = append(errs, errors.New("loadBalancer statsPort must differ from apiServerPort"))
+		}
+	}
+	// a custom load balancer config template should parse
+	if c.LoadBalancer.ConfigTemplate != "" {
+		if _, err := lbtemplate.Parse("loadbalancer-config", c.LoadBalancer.ConfigTemplate); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid loadBalancer configTemplate"))
+		}
+	}
+
+	// kube-proxy mode should be one of the supported modes
+	switch c.Networking.KubeProxyMode {
+	case IPTablesProxyMode,
+		IPVSProxyMode,
+		NoneProxyMode:
+	default:
+		errs = append(errs, errors.Errorf("invalid kubeProxyMode: %q", c.Networking.KubeProxyMode))
+	}
+
+	// feature gates and runtime config are passed to components as
+	// key=value,key2=value2 flags
+	for gate := range c.FeatureGates {
+		if !validFlagMapKey(gate) {
+			errs = append(errs, errors.Errorf("invalid feature gate name: %q", gate))
+		}
+	}
+	for key, value := range c.RuntimeConfig {
+		if !validFlagMapKey(key) || strings.Contains(value, ",") {
+			errs = append(errs, errors.Errorf("invalid runtimeConfig entry: %q: %q", key, value))
+		}
+	}
+
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
 	// All nodes in the config should be valid
@@ -61,6 +149,60 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
+	// each host port can only be bound by one node
+	errs = append(errs, validateHostPorts(c.Nodes)...)
+
+	// validate kubeadm config patch selectors
+	for i, p := range c.KubeadmConfigPatchesJSON6902 {
+		if p.Nodes == nil {
+			continue
+		}
+		if err := p.Nodes.Validate(len(c.Nodes)); err != nil {
+			errs = append(errs, errors.Errorf("invalid nodes for kubeadmConfigPatchesJSON6902 %d: %v", i, err))
+		}
+	}
+	for i, p := range c.KubeadmConfigSelectedPatches {
+		if err := p.Nodes.Validate(len(c.Nodes)); err != nil {
+			errs = append(errs, errors.Errorf("invalid nodes for kubeadmConfigSelectedPatches %d: %v", i, err))
+		}
+	}
+
+	// validate manifests
+	for i, m := range c.Manifests {
+		if err := m.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid manifest %d: %v", i, err))
+		}
+	}
+
+	// validate storage
+	if err := c.Storage.Validate(); err != nil {
+		errs = append(errs, errors.Errorf("invalid storage: %v", err))
+	}
+
+	// validate hooks
+	for _, phase := range []struct {
+		name  string
+		hooks []Hook
+	}{
+		{"postProvision", c.Hooks.PostProvision},
+		{"preKubeadmInit", c.Hooks.PreKubeadmInit},
+		{"postJoin", c.Hooks.PostJoin},
+		{"postCreate", c.Hooks.PostCreate},
+	} {
+		for i, h := range phase.hooks {
+			if err := h.Validate(); err != nil {
+				errs = append(errs, errors.Errorf("invalid %s hook %d: %v", phase.name, i, err))
+			}
+		}
+	}
+
+	// validate files
+	for i, f := range c.Files {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid file %d: %v", i, err))
+		}
+	}
+
 	// there must be at least one control plane node
 	numControlPlane, anyControlPlane := numByRole[ControlPlaneRole]
 	if !anyControlPlane || numControlPlane < 1 {
@@ -101,13 +243,234 @@ func (n *Node) Validate() error {
 		}
 	}
 
+	// validate ingress ports
+	if n.Ingress != nil {
+		if err := validatePort(n.Ingress.HTTPPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid ingress httpPort"))
+		}
+		if err := validatePort(n.Ingress.HTTPSPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid ingress httpsPort"))
+		}
+	}
+
+	// node-level patches always target the node itself
+	for i, p := range n.KubeadmConfigPatchesJSON6902 {
+		if p.Nodes != nil {
+			errs = append(errs, errors.Errorf("nodes cannot be set for node-level kubeadmConfigPatchesJSON6902 %d", i))
+		}
+	}
+
+	// validate files
+	for i, f := range n.Files {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid file %d", i))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the NodeSelector, or nil if there are none
+// numNodes is the number of nodes in the cluster, for checking indexes
+func (s *NodeSelector) Validate(numNodes int) error {
+	errs := []error{}
+
+	for _, role := range s.Roles {
+		switch role {
+		case ControlPlaneRole,
+			WorkerRole:
+		default:
+			errs = append(errs, errors.Errorf("%q is not a valid node role", role))
+		}
+	}
+	for _, index := range s.Indexes {
+		if index < 0 || int(index) >= numNodes {
+			errs = append(errs, errors.Errorf("node index %d is out of range", index))
+		}
+	}
+	for _, name := range s.Names {
+		if _, err := path.Match(name, ""); err != nil {
+			errs = append(errs, errors.Errorf("invalid name pattern %q", name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Storage, or nil if there are none
+func (s *Storage) Validate() error {
+	errs := []error{}
+
+	// the default StorageClass can be disabled, or replaced, but not both
+	if s.DisableDefaultStorageClass && s.Manifest != nil {
+		errs = append(errs, errors.New("manifest cannot be set when disableDefaultStorageClass is set"))
+	}
+	if s.NonDefaultStorageClass && (s.DisableDefaultStorageClass || s.Manifest != nil) {
+		errs = append(errs, errors.New("nonDefaultStorageClass only applies to the default StorageClass"))
+	}
+	if s.PersistentHostPath != "" && (s.DisableDefaultStorageClass || s.Manifest != nil) {
+		errs = append(errs, errors.New("persistentHostPath only applies to the default StorageClass"))
+	}
+	if s.Manifest != nil {
+		if err := s.Manifest.Validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid manifest"))
+		}
+	}
+	if !path.IsAbs(s.LocalPath) {
+		errs = append(errs, errors.Errorf("localPath must be an absolute path: %q", s.LocalPath))
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
+	return nil
+}
+
+// Validate returns an error if the Manifest is invalid
+func (m *Manifest) Validate() error {
+	if (m.Path == "") == (m.Content == "") {
+		return errors.New("exactly one of path and content must be set")
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Hook, or nil if there are none
+func (h *Hook) Validate() error {
+	errs := []error{}
 
+	if len(h.Command) == 0 || h.Command[0] == "" {
+		errs = append(errs, errors.New("command is a required field"))
+	}
+
+	if h.RunOnHost && len(h.Roles) > 0 {
+		errs = append(errs, errors.New("roles cannot be set for hooks that run on the host"))
+	}
+	for _, role := range h.Roles {
+		switch role {
+		case ControlPlaneRole,
+			WorkerRole:
+		default:
+			errs = append(errs, errors.Errorf("%q is not a valid node role", role))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
 	return nil
 }
 
+// Validate returns a ConfigErrors with an entry for each problem
+// with the File, or nil if there are none
+func (f *File) Validate() error {
+	errs := []error{}
+
+	if !path.IsAbs(f.Path) {
+		errs = append(errs, errors.Errorf("path must be absolute: %q", f.Path))
+	}
+	if (f.Content == "") == (f.HostPath == "") {
+		errs = append(errs, errors.New("exactly one of content and hostPath must be set"))
+	}
+	if f.Permissions != "" {
+		if mode, err := strconv.ParseUint(f.Permissions, 8, 32); err != nil || mode > 07777 {
+			errs = append(errs, errors.Errorf("invalid permissions: %q", f.Permissions))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// validateSubnets validates a CIDR, or a comma separated IPv4 and IPv6 CIDR
+// pair if dualStack is set
+func validateSubnets(subnets string, dualStack bool) error {
+	cidrs := strings.Split(subnets, ",")
+	if !dualStack {
+		if len(cidrs) != 1 {
+			return errors.New("only dual stack clusters may have more than one subnet")
+		}
+		_, _, err := net.ParseCIDR(cidrs[0])
+		return err
+	}
+	if len(cidrs) != 2 {
+		return errors.Errorf("dual stack clusters require an IPv4 and an IPv6 subnet, got %q", subnets)
+	}
+	ipv6s := 0
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		if ip.To4() == nil {
+			ipv6s++
+		}
+	}
+	if ipv6s != 1 {
+		return errors.Errorf("dual stack clusters require an IPv4 and an IPv6 subnet, got %q", subnets)
+	}
+	return nil
+}
+
+func validFlagMapKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, "=,")
+}
+
+// validateHostPorts returns an error for each host port that is bound by
+// more than one of nodes, random (zero) host ports are never in conflict
+func validateHostPorts(nodes []Node) []error {
+	type hostPort struct {
+		port     int32
+		protocol PortMappingProtocol
+	}
+	type claim struct {
+		node    int
+		address string
+	}
+	// the empty address and unspecified addresses bind every address
+	isAny := func(address string) bool {
+		ip := net.ParseIP(address)
+		return address == "" || (ip != nil && ip.IsUnspecified())
+	}
+
+	errs := []error{}
+	claims := map[hostPort][]claim{}
+	for i := range nodes {
+		for _, mapping := range nodes[i].PortMappings() {
+			if mapping.HostPort == 0 {
+				continue
+			}
+			key := hostPort{port: mapping.HostPort, protocol: mapping.Protocol}
+			if key.protocol == "" {
+				key.protocol = PortMappingProtocolTCP
+			}
+			for _, other := range claims[key] {
+				if isAny(other.address) || isAny(mapping.ListenAddress) || other.address == mapping.ListenAddress {
+					if other.node == i {
+						errs = append(errs, errors.Errorf("host port %d/%s is bound more than once by node %d", key.port, key.protocol, i))
+					} else {
+						errs = append(errs, errors.Errorf("host port %d/%s is bound by both node %d and node %d", key.port, key.protocol, other.node, i))
+					}
+					break
+				}
+			}
+			claims[key] = append(claims[key], claim{node: i, address: mapping.ListenAddress})
+		}
+	}
+	return errs
+}
+
 func validatePort(port int32) error {
 	if port < 0 || port > 65535 {
 		return errors.Errorf("invalid port number: %d", port)
@@ -55,6 +55,47 @@ func TestClusterValidate(t *testing.T) {
 				return c
 			}(),
 		},
+		{
+			Name: "default dual stack",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.IPFamily = DualStackFamily
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+		},
+		{
+			Name: "dual stack with two IPv4 subnets",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.IPFamily = DualStackFamily
+				c.Networking.PodSubnet = "10.244.0.0/16,10.245.0.0/16"
+				c.Networking.ServiceSubnet = "10.96.0.0/12"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "two subnets without dual stack",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.PodSubnet = "10.244.0.0/16,fd00:10:244::/56"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus ipFamily",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.IPFamily = "ipv5"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "bogus podSubnet",
 			Cluster: func() Cluster {
@@ -95,6 +136,254 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "ipvs kube-proxy",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.KubeProxyMode = IPVSProxyMode
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+		},
+		{
+			Name: "bogus kube-proxy mode",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.KubeProxyMode = "userspace"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "network policy with the default CNI",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.EnableNetworkPolicy = true
+				return c
+			}(),
+		},
+		{
+			Name: "network policy without the default CNI",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.EnableNetworkPolicy = true
+				c.Networking.DisableDefaultCNI = true
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "load balancer with an address pool",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.EnableLoadBalancer = true
+				c.Networking.LoadBalancerAddressPool = "172.18.255.192/27"
+				return c
+			}(),
+		},
+		{
+			Name: "bogus load balancer address pool",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.LoadBalancerAddressPool = "172.18.255.192"
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "ingress nodes on different host ports",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Nodes = []Node{
+					{Role: ControlPlaneRole, Ingress: &Ingress{}},
+					{Role: WorkerRole, Ingress: &Ingress{HTTPPort: 8080, HTTPSPort: 8443}},
+					{Role: WorkerRole, Ingress: &Ingress{ListenAddress: "127.0.0.2"}, ExtraPortMappings: []PortMapping{{ContainerPort: 80, HostPort: 80, ListenAddress: "127.0.0.3"}}},
+				}
+				c.Nodes[0].Ingress.ListenAddress = "127.0.0.1"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+		},
+		{
+			Name: "ingress nodes claiming the same host ports",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Nodes = []Node{
+					{Role: ControlPlaneRole, Ingress: &Ingress{}},
+					{Role: WorkerRole, Ingress: &Ingress{ListenAddress: "127.0.0.1"}},
+					{Role: WorkerRole, ExtraPortMappings: []PortMapping{{ContainerPort: 80, HostPort: 8080, Protocol: PortMappingProtocolUDP}, {ContainerPort: 81, HostPort: 8080}}},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "valid feature gates and runtime config",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.FeatureGates = map[string]bool{"EphemeralContainers": true}
+				c.RuntimeConfig = map[string]string{"api/alpha": "true"}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus feature gates and runtime config",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.FeatureGates = map[string]bool{"": true, "A=B": false}
+				c.RuntimeConfig = map[string]string{"api/alpha": "true,false"}
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "valid patch selectors",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.KubeadmConfigPatchesJSON6902 = []PatchJSON6902{{Kind: "ClusterConfiguration", Nodes: &NodeSelector{Indexes: []int32{0}}}}
+				c.KubeadmConfigSelectedPatches = []SelectedPatch{{Nodes: NodeSelector{Roles: []NodeRole{ControlPlaneRole}, Names: []string{"*-control-plane*"}}}}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus patch selectors",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.KubeadmConfigPatchesJSON6902 = []PatchJSON6902{{Kind: "ClusterConfiguration", Nodes: &NodeSelector{Indexes: []int32{1}}}}
+				c.KubeadmConfigSelectedPatches = []SelectedPatch{{Nodes: NodeSelector{Roles: []NodeRole{"bogus"}, Names: []string{"["}}}}
+				c.Nodes[0].KubeadmConfigPatchesJSON6902 = []PatchJSON6902{{Kind: "ClusterConfiguration", Nodes: &NodeSelector{}}}
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "valid manifests",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Manifests = []Manifest{{Path: "./crds"}, {Content: "kind: Namespace", WaitForRollout: true}}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus manifests",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Manifests = []Manifest{{}, {Path: "./crds", Content: "kind: Namespace"}}
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "valid hooks",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Hooks.PreKubeadmInit = []Hook{{Command: []string{"update-ca-certificates"}, Roles: []NodeRole{WorkerRole}}}
+				c.Hooks.PostCreate = []Hook{{Command: []string{"./post-create.sh"}, RunOnHost: true}}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus hooks",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Hooks.PostProvision = []Hook{{Name: "no command"}}
+				c.Hooks.PostJoin = []Hook{{Command: []string{"true"}, RunOnHost: true, Roles: []NodeRole{"bogus"}}}
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "valid files",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Files = []File{{Path: "/etc/kubernetes/audit-policy.yaml", HostPath: "./audit-policy.yaml", Permissions: "0600"}}
+				c.Nodes[0].Files = []File{{Path: "/etc/motd", Content: "hello", Owner: "root:root"}}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus files",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Files = []File{{Path: "relative", Content: "hello", Permissions: "rw"}}
+				c.Nodes[0].Files = []File{{Path: "/etc/motd"}}
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus load balancer",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.LoadBalancer.Type = "traefik"
+				c.LoadBalancer.ConnectTimeout = "5"
+				c.LoadBalancer.ServerTimeout = "-1s"
+				c.LoadBalancer.StatsPort = 70000
+				return c
+			}(),
+			ExpectErrors: 4,
+		},
+		{
+			Name: "load balancer config template",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.LoadBalancer.ConfigTemplate = "bind *:{{ .ControlPlanePort }}\n{{ range .BackendServers }}{{ host . }}:{{ port . }}{{ end }}"
+				return c
+			}(),
+		},
+		{
+			Name: "bogus load balancer config template",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.LoadBalancer.ConfigTemplate = "{{ range .BackendServers }}{{ address . }}"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "non default storage in a custom directory",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Storage.NonDefaultStorageClass = true
+				c.Storage.LocalPath = "/data"
+				c.Storage.PersistentHostPath = "./data"
+				return c
+			}(),
+		},
+		{
+			Name: "bogus storage",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Storage.DisableDefaultStorageClass = true
+				c.Storage.NonDefaultStorageClass = true
+				c.Storage.Manifest = &Manifest{}
+				c.Storage.LocalPath = "data"
+				c.Storage.PersistentHostPath = "./data"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "bogus node",
 			Cluster: func() Cluster {
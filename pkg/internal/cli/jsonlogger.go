@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// JSONLogger is a log.Logger and log.EventSink implementation that writes
+// every message and event as a single line of JSON, for machine consumption
+type JSONLogger struct {
+	writer    io.Writer
+	writerMu  sync.Mutex
+	verbosity log.Level
+}
+
+var _ log.Logger = &JSONLogger{}
+var _ log.EventSink = &JSONLogger{}
+
+// NewJSONLogger returns a new JSONLogger with the given verbosity
+func NewJSONLogger(writer io.Writer, verbosity log.Level) *JSONLogger {
+	return &JSONLogger{
+		writer:    writer,
+		verbosity: verbosity,
+	}
+}
+
+// SetWriter sets the output writer
+func (l *JSONLogger) SetWriter(w io.Writer) {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	l.writer = w
+}
+
+func (l *JSONLogger) getVerbosity() log.Level {
+	return log.Level(atomic.LoadInt32((*int32)(&l.verbosity)))
+}
+
+// SetVerbosity sets the loggers verbosity
+func (l *JSONLogger) SetVerbosity(verbosity log.Level) {
+	atomic.StoreInt32((*int32)(&l.verbosity), int32(verbosity))
+}
+
+// Event is part of the log.EventSink interface
+func (l *JSONLogger) Event(e log.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		// this should not be possible, Event only contains simple types
+		return
+	}
+	b = append(b, '\n')
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	_, _ = l.writer.Write(b)
+}
+
+// message writes a simple message event, trailing newlines are meaningless
+// in JSON output so they are dropped
+func (l *JSONLogger) message(t log.EventType, message string) {
+	l.Event(log.Event{
+		Type:    t,
+		Message: strings.TrimRight(message, "\n"),
+	})
+}
+
+// Warn is part of the log.Logger interface
+func (l *JSONLogger) Warn(message string) {
+	l.message(log.WarningEvent, message)
+}
+
+// Warnf is part of the log.Logger interface
+func (l *JSONLogger) Warnf(format string, args ...interface{}) {
+	l.message(log.WarningEvent, fmt.Sprintf(format, args...))
+}
+
+// Error is part of the log.Logger interface
+func (l *JSONLogger) Error(message string) {
+	l.message(log.ErrorEvent, message)
+}
+
+// Errorf is part of the log.Logger interface
+func (l *JSONLogger) Errorf(format string, args ...interface{}) {
+	l.message(log.ErrorEvent, fmt.Sprintf(format, args...))
+}
+
+// V is part of the log.Logger interface
+func (l *JSONLogger) V(level log.Level) log.InfoLogger {
+	return jsonInfoLogger{
+		logger:  l,
+		enabled: level <= l.getVerbosity(),
+	}
+}
+
+// jsonInfoLogger implements log.InfoLogger for JSONLogger
+type jsonInfoLogger struct {
+	logger  *JSONLogger
+	enabled bool
+}
+
+// Enabled is part of the log.InfoLogger interface
+func (i jsonInfoLogger) Enabled() bool {
+	return i.enabled
+}
+
+// Info is part of the log.InfoLogger interface
+func (i jsonInfoLogger) Info(message string) {
+	if !i.enabled {
+		return
+	}
+	i.logger.message(log.InfoEvent, message)
+}
+
+// Infof is part of the log.InfoLogger interface
+func (i jsonInfoLogger) Infof(format string, args ...interface{}) {
+	if !i.enabled {
+		return
+	}
+	i.logger.message(log.InfoEvent, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestJSONLogger(t *testing.T) {
+	t.Parallel()
+	buff := &bytes.Buffer{}
+	l := NewJSONLogger(buff, 0)
+	ts := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	success := false
+	l.Warnf("cluster name %q is probably too long", "foo")
+	l.V(0).Info("Creating cluster \"kind\" ...\n")
+	l.V(1).Info("this should not be written")
+	l.Event(log.Event{
+		Time:    ts,
+		Type:    log.ActionEndEvent,
+		Step:    "kubeadminit",
+		Success: &success,
+		Error:   "failed to init node with kubeadm",
+	})
+	lines := bytes.Split(bytes.TrimSpace(buff.Bytes()), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines of output but got %d: %q", len(lines), buff.String())
+	}
+	assert.StringEqual(t,
+		`{"time":"2019-11-01T00:00:00Z","type":"ActionEnd","step":"kubeadminit","success":false,"error":"failed to init node with kubeadm"}`,
+		string(lines[2]),
+	)
+	for _, line := range lines[:2] {
+		if !bytes.HasPrefix(line, []byte(`{"time":"`)) {
+			t.Errorf("expected JSON event line but got: %q", line)
+		}
+	}
+	if !bytes.Contains(lines[0], []byte(`"type":"Warning","message":"cluster name \"foo\" is probably too long"`)) {
+		t.Errorf("unexpected warning event: %q", lines[0])
+	}
+	if !bytes.Contains(lines[1], []byte(`"type":"Info","message":"Creating cluster \"kind\" ..."}`)) {
+		t.Errorf("unexpected info event: %q", lines[1])
+	}
+}
+
+func TestStatusForEventSink(t *testing.T) {
+	t.Parallel()
+	buff := &bytes.Buffer{}
+	status := StatusForLogger(NewJSONLogger(buff, 0))
+	status.Start("Writing configuration 📜")
+	status.End(true)
+	status.Start("Starting control-plane 🕹️")
+	status.EndWithError(errors.New("failed to init node with kubeadm"))
+	// the status has already ended, so this should not be written
+	status.EndWithError(errors.New("ended twice"))
+	lines := bytes.Split(bytes.TrimSpace(buff.Bytes()), []byte("\n"))
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines of output but got %d: %q", len(lines), buff.String())
+	}
+	if !bytes.Contains(lines[0], []byte(`"type":"StatusStart","step":"Writing configuration 📜"}`)) {
+		t.Errorf("unexpected status start event: %q", lines[0])
+	}
+	if !bytes.Contains(lines[1], []byte(`"type":"StatusEnd","step":"Writing configuration 📜","success":true}`)) {
+		t.Errorf("unexpected status end event: %q", lines[1])
+	}
+	if !bytes.Contains(lines[3], []byte(`"type":"StatusEnd","step":"Starting control-plane 🕹️","success":false,"error":"failed to init node with kubeadm"}`)) {
+		t.Errorf("unexpected failed status end event: %q", lines[3])
+	}
+}
@@ -18,6 +18,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/log"
 )
@@ -28,6 +29,8 @@ type Status struct {
 	spinner *Spinner
 	status  string
 	logger  log.Logger
+	// if set, status changes are reported as events instead of messages
+	sink log.EventSink
 	// for controlling coloring etc
 	successFormat string
 	failureFormat string
@@ -36,12 +39,17 @@ type Status struct {
 // StatusForLogger returns a new status object for the logger l,
 // if l is the kind cli logger and the writer is a Spinner, that spinner
 // will be used for the status
+// if l is a log.EventSink, status changes will be sent to it as events
 func StatusForLogger(l log.Logger) *Status {
 	s := &Status{
 		logger:        l,
 		successFormat: " ✓ %s\n",
 		failureFormat: " ✗ %s\n",
 	}
+	if v, ok := l.(log.EventSink); ok {
+		s.sink = v
+		return s
+	}
 	// if we're using the CLI logger, check for if it has a spinner setup
 	// and wire the status to that
 	if v, ok := l.(*Logger); ok {
@@ -61,7 +69,13 @@ func (s *Status) Start(status string) {
 	s.End(true)
 	// set new status
 	s.status = status
-	if s.spinner != nil {
+	if s.sink != nil {
+		s.sink.Event(log.Event{
+			Time: time.Now().UTC(),
+			Type: log.StatusStartEvent,
+			Step: s.status,
+		})
+	} else if s.spinner != nil {
 		s.spinner.SetSuffix(fmt.Sprintf(" %s ", s.status))
 		s.spinner.Start()
 	} else {
@@ -72,10 +86,36 @@ func (s *Status) Start(status string) {
 // End completes the current status, ending any previous spinning and
 // marking the status as success or failure
 func (s *Status) End(success bool) {
+	s.end(success, "")
+}
+
+// EndWithError completes the current status like End, marking it as failed
+// if err is not nil. The error is included in the StatusEnd event.
+func (s *Status) EndWithError(err error) {
+	if err != nil {
+		s.end(false, err.Error())
+		return
+	}
+	s.end(true, "")
+}
+
+func (s *Status) end(success bool, errText string) {
 	if s.status == "" {
 		return
 	}
 
+	if s.sink != nil {
+		s.sink.Event(log.Event{
+			Time:    time.Now().UTC(),
+			Type:    log.StatusEndEvent,
+			Step:    s.status,
+			Success: &success,
+			Error:   errText,
+		})
+		s.status = ""
+		return
+	}
+
 	if s.spinner != nil {
 		s.spinner.Stop()
 		fmt.Fprint(s.spinner.writer, "\r")
@@ -0,0 +1,221 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Finding is a Signature found in the collected logs
+type Finding struct {
+	Signature Signature
+	// Count is the number of matching lines
+	Count int
+	// File is the first file with a matching line
+	File string
+	// Line is the first matching line
+	Line string
+}
+
+// maxLineSize is the longest line that will be scanned
+const maxLineSize = 1024 * 1024
+
+// maxEvidence is the longest matching line kept in a Finding
+const maxEvidence = 200
+
+// maxInspectSize is the largest inspect.json that will be read for node IPs
+const maxInspectSize = 1024 * 1024
+
+// Analyzer scans files for Signatures
+//
+// It also checks the node IPs against the host routes once all files were
+// scanned, see routeConflict
+type Analyzer struct {
+	signatures []Signature
+	findings   map[string]*Finding
+	nodeIPs    []nodeIP
+	routes     []route
+}
+
+// NewAnalyzer returns an Analyzer for signatures
+func NewAnalyzer(signatures []Signature) *Analyzer {
+	return &Analyzer{
+		signatures: signatures,
+		findings:   map[string]*Finding{},
+	}
+}
+
+// Scan scans the file name, relative to the root of the collected logs,
+// for the signatures that apply to it
+func (a *Analyzer) Scan(name string, r io.Reader) error {
+	if matched, _ := path.Match(nodeInspectFiles, name); matched {
+		raw, err := ioutil.ReadAll(io.LimitReader(r, maxInspectSize))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", name)
+		}
+		a.nodeIPs = append(a.nodeIPs, inspectIPs(raw)...)
+		r = bytes.NewReader(raw)
+	}
+	routes := name == hostRoutesFile
+
+	applicable := []*Signature{}
+	for i := range a.signatures {
+		if a.signatures[i].AppliesTo(name) {
+			applicable = append(applicable, &a.signatures[i])
+		}
+	}
+	if len(applicable) == 0 && !routes {
+		return nil
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if routes {
+			if rt, ok := parseRoute(line); ok {
+				a.routes = append(a.routes, rt)
+			}
+		}
+		for _, s := range applicable {
+			if !s.Matches(line) {
+				continue
+			}
+			f, ok := a.findings[s.Name]
+			if !ok {
+				f = &Finding{Signature: *s, File: name, Line: truncate(strings.TrimSpace(line), maxEvidence)}
+				a.findings[s.Name] = f
+			}
+			f.Count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "failed to scan %s", name)
+	}
+	return nil
+}
+
+// Findings returns the signatures found, most likely cause first: by
+// severity, then by number of matching lines
+func (a *Analyzer) Findings() []Finding {
+	findings := make([]Finding, 0, len(a.findings)+1)
+	for _, f := range a.findings {
+		findings = append(findings, *f)
+	}
+	if f := routeConflictFinding(a.nodeIPs, a.routes); f != nil {
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Signature.Severity != findings[j].Signature.Severity {
+			return findings[i].Signature.Severity > findings[j].Signature.Severity
+		}
+		if findings[i].Count != findings[j].Count {
+			return findings[i].Count > findings[j].Count
+		}
+		return findings[i].Signature.Name < findings[j].Signature.Name
+	})
+	return findings
+}
+
+// AnalyzeDir scans the logs collected to dir for the known Signatures
+func AnalyzeDir(dir string) ([]Finding, error) {
+	a := NewAnalyzer(Signatures())
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		name, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return a.Scan(filepath.ToSlash(name), f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.Findings(), nil
+}
+
+// AnalyzeArchive scans the logs collected to the gzip compressed tar archive
+// at archivePath for the known Signatures
+func AnalyzeArchive(archivePath string) ([]Finding, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read logs archive")
+	}
+	a := NewAnalyzer(Signatures())
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		switch {
+		case err == io.EOF:
+			return a.Findings(), nil
+		case err != nil:
+			return nil, errors.Wrap(err, "failed to read logs archive")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := a.Scan(hdr.Name, tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WriteReport writes a human readable report of findings to w
+func WriteReport(w io.Writer, findings []Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "No known problems found in the logs.")
+		return
+	}
+	fmt.Fprintf(w, "Found %d known problem(s) in the logs, most likely cause first:\n", len(findings))
+	for i, f := range findings {
+		fmt.Fprintf(w, "\n%d. [%s] %s: %s\n", i+1, f.Signature.Severity, f.Signature.Name, f.Signature.Summary)
+		fmt.Fprintf(w, "   seen %d time(s), first in %s:\n", f.Count, f.File)
+		fmt.Fprintf(w, "     %s\n", f.Line)
+		fmt.Fprintf(w, "   hint: %s\n", f.Signature.Remediation)
+	}
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzerFindings(t *testing.T) {
+	t.Parallel()
+	a := NewAnalyzer(Signatures())
+	files := map[string]string{
+		"kind-control-plane/kubelet.log": strings.Join([]string{
+			"I0101 kubelet started",
+			"E0101 eviction_manager.go:340] eviction manager: must evict pod(s) to reclaim ephemeral-storage",
+			"E0101 eviction_manager.go:340] eviction manager: must evict pod(s) to reclaim ephemeral-storage",
+		}, "\n"),
+		"kind-worker/kubelet.log": strings.Join([]string{
+			"E0101 dial tcp 172.17.0.2:6443: connect: network is unreachable",
+			"E0101 failed to create fsnotify watcher: too many open files",
+			"E0101 failed to create fsnotify watcher: too many open files",
+			"E0101 failed to create fsnotify watcher: too many open files",
+		}, "\n"),
+		"docker-info.txt": "Cgroup Driver: cgroupfs",
+	}
+	for name, contents := range files {
+		if err := a.Scan(name, strings.NewReader(contents)); err != nil {
+			t.Fatalf("unexpected error scanning %s: %v", name, err)
+		}
+	}
+
+	// ranked by severity, then by count
+	expected := []struct {
+		Name  string
+		Count int
+		File  string
+	}{
+		{Name: "network-conflict", Count: 1, File: "kind-worker/kubelet.log"},
+		{Name: "inotify", Count: 3, File: "kind-worker/kubelet.log"},
+		{Name: "disk-pressure", Count: 2, File: "kind-control-plane/kubelet.log"},
+	}
+	findings := a.Findings()
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings but got %d: %v", len(expected), len(findings), findings)
+	}
+	for i, e := range expected {
+		f := findings[i]
+		if f.Signature.Name != e.Name || f.Count != e.Count || f.File != e.File {
+			t.Errorf("expected finding %d to be %s seen %d times in %s, got %s seen %d times in %s",
+				i, e.Name, e.Count, e.File, f.Signature.Name, f.Count, f.File)
+		}
+	}
+}
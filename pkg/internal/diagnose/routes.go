@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// the files the node IPs and the host routes are read from
+const (
+	nodeInspectFiles = "*/inspect.json"
+	hostRoutesFile   = "host-routes.txt"
+)
+
+// routeConflict is found by checking the node IPs in the nodes' inspect.json
+// against the host routes, rather than by matching lines
+var routeConflict = Signature{
+	Name:     "route-conflict",
+	Severity: SeverityCritical,
+	Summary:  "a host route outside of docker takes precedence for node IPs, eg from a VPN, so traffic to the nodes does not reach them",
+	Remediation: "configure the docker bridge network (bip in /etc/docker/daemon.json) or the conflicting network, " +
+		"eg the VPN, to not overlap, see " + knownIssues,
+	Files: []string{nodeInspectFiles, hostRoutesFile},
+}
+
+// nodeIP is the IP of a node on a docker network
+type nodeIP struct {
+	node string
+	ip   net.IP
+}
+
+// route is a host route from `ip route show`
+type route struct {
+	line string
+	dst  *net.IPNet
+	dev  string
+}
+
+// inspectIPs returns the IPs of the nodes in the `docker inspect` output raw,
+// or nothing if it cannot be parsed, eg if inspecting the node failed
+func inspectIPs(raw []byte) []nodeIP {
+	containers := []struct {
+		Name            string
+		NetworkSettings struct {
+			Networks map[string]struct {
+				IPAddress         string
+				GlobalIPv6Address string
+			}
+		}
+	}{}
+	if err := json.Unmarshal(raw, &containers); err != nil {
+		return nil
+	}
+	ips := []nodeIP{}
+	for _, c := range containers {
+		// sort the networks for stable results
+		networks := make([]string, 0, len(c.NetworkSettings.Networks))
+		for name := range c.NetworkSettings.Networks {
+			networks = append(networks, name)
+		}
+		sort.Strings(networks)
+		for _, name := range networks {
+			network := c.NetworkSettings.Networks[name]
+			for _, addr := range []string{network.IPAddress, network.GlobalIPv6Address} {
+				if ip := net.ParseIP(addr); ip != nil {
+					ips = append(ips, nodeIP{node: strings.TrimPrefix(c.Name, "/"), ip: ip})
+				}
+			}
+		}
+	}
+	return ips
+}
+
+// parseRoute parses a line of `ip route show`, default routes and lines
+// that are not routes are ignored
+func parseRoute(line string) (route, bool) {
+	fields := strings.Fields(line)
+	// skip the route type, if any
+	if len(fields) > 0 {
+		switch fields[0] {
+		case "unicast", "unreachable", "blackhole", "prohibit", "throw":
+			fields = fields[1:]
+		}
+	}
+	if len(fields) == 0 || fields[0] == "default" {
+		return route{}, false
+	}
+	dst := fields[0]
+	if !strings.Contains(dst, "/") {
+		if ip := net.ParseIP(dst); ip != nil && ip.To4() != nil {
+			dst += "/32"
+		} else {
+			dst += "/128"
+		}
+	}
+	_, dstNet, err := net.ParseCIDR(dst)
+	if err != nil {
+		return route{}, false
+	}
+	r := route{line: strings.TrimSpace(line), dst: dstNet}
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "dev" {
+			r.dev = fields[i+1]
+		}
+	}
+	return r, true
+}
+
+// dockerBridge returns true if dev is a docker bridge network interface,
+// whose routes are expected to cover the node IPs
+func dockerBridge(dev string) bool {
+	return dev == "docker0" || strings.HasPrefix(dev, "br-")
+}
+
+// routeConflictFinding returns a Finding for the routes outside of docker
+// that take precedence over the docker bridge routes for any of ips, or nil
+// if there are none
+//
+// The most specific route to an IP is used, so a route outside of docker only
+// conflicts if it is at least as specific as the docker bridge route to the
+// IP, if there is one.
+func routeConflictFinding(ips []nodeIP, routes []route) *Finding {
+	// the prefix length of the most specific docker bridge route to each IP
+	bridgePrefix := make([]int, len(ips))
+	for i, ip := range ips {
+		bridgePrefix[i] = -1
+		for _, r := range routes {
+			if ones, _ := r.dst.Mask.Size(); dockerBridge(r.dev) && r.dst.Contains(ip.ip) && ones > bridgePrefix[i] {
+				bridgePrefix[i] = ones
+			}
+		}
+	}
+	var finding *Finding
+	for _, r := range routes {
+		if dockerBridge(r.dev) {
+			continue
+		}
+		for i, ip := range ips {
+			if !r.dst.Contains(ip.ip) {
+				continue
+			}
+			if ones, _ := r.dst.Mask.Size(); ones < bridgePrefix[i] {
+				continue
+			}
+			if finding == nil {
+				finding = &Finding{
+					Signature: routeConflict,
+					File:      hostRoutesFile,
+					Line:      truncate(fmt.Sprintf("%s covers %s IP %s", r.line, ip.node, ip.ip), maxEvidence),
+				}
+			}
+			finding.Count++
+		}
+	}
+	return finding
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"strings"
+	"testing"
+)
+
+const testInspect = `[
+    {
+        "Name": "/kind-control-plane",
+        "NetworkSettings": {
+            "Networks": {
+                "kind": {
+                    "IPAddress": "172.18.0.2",
+                    "GlobalIPv6Address": "fc00:f853:ccd:e793::2"
+                }
+            }
+        }
+    }
+]`
+
+func TestParseRoute(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Line        string
+		ExpectedDst string
+		ExpectedDev string
+		ExpectRoute bool
+	}{
+		{
+			Line:        "172.18.0.0/16 dev br-3d0c2c4d1a7e proto kernel scope link src 172.18.0.1",
+			ExpectedDst: "172.18.0.0/16",
+			ExpectedDev: "br-3d0c2c4d1a7e",
+			ExpectRoute: true,
+		},
+		{
+			Line:        "172.16.0.0/12 via 10.8.0.1 dev tun0",
+			ExpectedDst: "172.16.0.0/12",
+			ExpectedDev: "tun0",
+			ExpectRoute: true,
+		},
+		{
+			Line:        "10.1.2.3 via 10.8.0.1 dev tun0",
+			ExpectedDst: "10.1.2.3/32",
+			ExpectedDev: "tun0",
+			ExpectRoute: true,
+		},
+		{
+			Line:        "unreachable 172.18.0.0/24 proto static",
+			ExpectedDst: "172.18.0.0/24",
+			ExpectRoute: true,
+		},
+		{
+			Line:        "fc00:f853:ccd:e793::/64 dev br-3d0c2c4d1a7e proto kernel metric 256 pref medium",
+			ExpectedDst: "fc00:f853:ccd:e793::/64",
+			ExpectedDev: "br-3d0c2c4d1a7e",
+			ExpectRoute: true,
+		},
+		{
+			Line: "default via 192.168.1.1 dev wlp2s0 proto dhcp metric 600",
+		},
+		{
+			Line: "sh: 1: ip: not found",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Line, func(t *testing.T) {
+			t.Parallel()
+			r, ok := parseRoute(tc.Line)
+			if ok != tc.ExpectRoute {
+				t.Fatalf("expected route %v but got %v", tc.ExpectRoute, ok)
+			}
+			if !ok {
+				return
+			}
+			if r.dst.String() != tc.ExpectedDst {
+				t.Errorf("expected destination %s but got %s", tc.ExpectedDst, r.dst)
+			}
+			if r.dev != tc.ExpectedDev {
+				t.Errorf("expected dev %q but got %q", tc.ExpectedDev, r.dev)
+			}
+		})
+	}
+}
+
+func TestAnalyzerRouteConflict(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name          string
+		Routes        []string
+		ExpectedCount int
+		ExpectedLine  string
+	}{
+		{
+			Name: "only the docker network covers the nodes",
+			Routes: []string{
+				"default via 192.168.1.1 dev wlp2s0 proto dhcp metric 600",
+				"172.17.0.0/16 dev docker0 proto kernel scope link src 172.17.0.1 linkdown",
+				"172.18.0.0/16 dev br-3d0c2c4d1a7e proto kernel scope link src 172.18.0.1",
+				"192.168.1.0/24 dev wlp2s0 proto kernel scope link src 192.168.1.10 metric 600",
+			},
+		},
+		{
+			Name: "less specific VPN route than the docker network",
+			Routes: []string{
+				"172.16.0.0/12 via 10.8.0.1 dev tun0",
+				"172.18.0.0/16 dev br-3d0c2c4d1a7e proto kernel scope link src 172.18.0.1",
+			},
+		},
+		{
+			Name: "VPN route as specific as the docker network",
+			Routes: []string{
+				"172.18.0.0/16 dev br-3d0c2c4d1a7e proto kernel scope link src 172.18.0.1",
+				"172.18.0.0/16 via 10.8.0.1 dev tun0 metric 50",
+			},
+			ExpectedCount: 1,
+			ExpectedLine:  "172.18.0.0/16 via 10.8.0.1 dev tun0 metric 50 covers kind-control-plane IP 172.18.0.2",
+		},
+		{
+			Name: "more specific VPN route than the docker network",
+			Routes: []string{
+				"172.16.0.0/12 via 10.8.0.1 dev tun0",
+				"172.18.0.0/16 dev br-3d0c2c4d1a7e proto kernel scope link src 172.18.0.1",
+				"172.18.0.0/24 via 10.8.0.1 dev tun0",
+			},
+			ExpectedCount: 1,
+			ExpectedLine:  "172.18.0.0/24 via 10.8.0.1 dev tun0 covers kind-control-plane IP 172.18.0.2",
+		},
+		{
+			Name: "VPN route without a docker network route",
+			Routes: []string{
+				"172.16.0.0/12 via 10.8.0.1 dev tun0",
+			},
+			ExpectedCount: 1,
+			ExpectedLine:  "172.16.0.0/12 via 10.8.0.1 dev tun0 covers kind-control-plane IP 172.18.0.2",
+		},
+		{
+			Name: "IPv6 route covers the nodes",
+			Routes: []string{
+				"fc00::/7 dev wg0 metric 1024 pref medium",
+			},
+			ExpectedCount: 1,
+			ExpectedLine:  "fc00::/7 dev wg0 metric 1024 pref medium covers kind-control-plane IP fc00:f853:ccd:e793::2",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			// the routes and nodes may be scanned in any order
+			for _, routesFirst := range []bool{true, false} {
+				a := NewAnalyzer(Signatures())
+				files := []struct {
+					name     string
+					contents string
+				}{
+					{"kind-control-plane/inspect.json", testInspect},
+					{hostRoutesFile, strings.Join(tc.Routes, "\n")},
+				}
+				if routesFirst {
+					files[0], files[1] = files[1], files[0]
+				}
+				for _, f := range files {
+					if err := a.Scan(f.name, strings.NewReader(f.contents)); err != nil {
+						t.Fatalf("unexpected error scanning %s: %v", f.name, err)
+					}
+				}
+				findings := a.Findings()
+				if tc.ExpectedCount == 0 {
+					if len(findings) != 0 {
+						t.Errorf("expected no findings but got %v", findings)
+					}
+					continue
+				}
+				if len(findings) != 1 || findings[0].Signature.Name != routeConflict.Name {
+					t.Fatalf("expected a %s finding but got %v", routeConflict.Name, findings)
+				}
+				if findings[0].Count != tc.ExpectedCount {
+					t.Errorf("expected count %d but got %d", tc.ExpectedCount, findings[0].Count)
+				}
+				if findings[0].Line != tc.ExpectedLine {
+					t.Errorf("expected line %q but got %q", tc.ExpectedLine, findings[0].Line)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectIPsInvalid(t *testing.T) {
+	t.Parallel()
+	if ips := inspectIPs([]byte("Error: No such object: kind-worker")); len(ips) != 0 {
+		t.Errorf("expected no IPs but got %v", ips)
+	}
+}
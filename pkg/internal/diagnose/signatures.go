@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"path"
+	"regexp"
+)
+
+// Severity ranks how likely a Signature is to have broken the cluster
+type Severity int
+
+const (
+	// SeverityWarning is for problems that may degrade the cluster
+	SeverityWarning Severity = iota
+	// SeverityHigh is for problems that commonly break workloads
+	SeverityHigh
+	// SeverityCritical is for problems that prevent the cluster working
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityCritical:
+		return "critical"
+	case SeverityHigh:
+		return "high"
+	default:
+		return "warning"
+	}
+}
+
+// Signature is a known failure, identified by matching lines of the
+// collected logs
+type Signature struct {
+	// Name identifies the signature
+	Name string
+	// Severity is used to rank the findings
+	Severity Severity
+	// Summary describes the failure
+	Summary string
+	// Remediation is a hint for fixing the failure
+	Remediation string
+	// Files are path.Match patterns for the files, relative to the root of
+	// the collected logs, that the signature applies to
+	Files []string
+	// Pattern matches lines showing the failure
+	Pattern *regexp.Regexp
+	// Exclude optionally matches lines that Pattern should not match
+	Exclude *regexp.Regexp
+}
+
+// AppliesTo returns true if the signature applies to the file name,
+// relative to the root of the collected logs
+func (s *Signature) AppliesTo(name string) bool {
+	for _, pattern := range s.Files {
+		// the patterns are constant and valid
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if line shows the failure
+func (s *Signature) Matches(line string) bool {
+	if !s.Pattern.MatchString(line) {
+		return false
+	}
+	return s.Exclude == nil || !s.Exclude.MatchString(line)
+}
+
+const knownIssues = "https://kind.sigs.k8s.io/docs/user/known-issues/"
+
+// the files collected by `kind export logs`
+var (
+	nodeJournals = []string{"*/journal.log", "*/kubelet.log", "*/containerd.log"}
+	nodeLogs     = append([]string{"*/serial.log"}, nodeJournals...)
+)
+
+// Signatures returns the known failure signatures
+func Signatures() []Signature {
+	return []Signature{
+		{
+			Name:     "cgroups",
+			Severity: SeverityCritical,
+			Summary:  "the kubelet cannot use the node cgroups, the cgroup driver or cgroup version of the host is not supported",
+			Remediation: "kind nodes expect the cgroupfs cgroup driver with cgroups v1, " +
+				"check \"Cgroup Driver\" in docker-info.txt and do not configure a different kubelet cgroup driver",
+			Files: nodeLogs,
+			Pattern: regexp.MustCompile(
+				`misconfiguration: kubelet cgroup driver|cgroup driver: ".*" is different from|` +
+					`cgroup mountpoint does not exist|expected cgroupsPath to be of format`,
+			),
+		},
+		{
+			Name:     "host-cgroups",
+			Severity: SeverityHigh,
+			Summary:  "docker on the host uses the systemd cgroup driver or cgroups v2, which the nodes may not support",
+			Remediation: "kind nodes expect docker to use the cgroupfs cgroup driver with cgroups v1, " +
+				"check \"Cgroup Driver\" and \"Cgroup Version\" in docker-info.txt, see " + knownIssues,
+			Files:   []string{"docker-info.txt"},
+			Pattern: regexp.MustCompile(`^\s*Cgroup (Driver: systemd|Version: 2)\s*$`),
+		},
+		{
+			Name:     "apparmor",
+			Severity: SeverityCritical,
+			Summary:  "AppArmor on the host is denying operations in the nodes",
+			Remediation: "check the host's AppArmor profiles for docker, eg with `sudo aa-status`, " +
+				"and see " + knownIssues,
+			Files: nodeLogs,
+			Pattern: regexp.MustCompile(
+				`apparmor="DENIED"|AppArmor enabled on system but|error loading apparmor profile|` +
+					`apparmor.*permission denied|permission denied.*apparmor`,
+			),
+		},
+		{
+			Name:     "network-conflict",
+			Severity: SeverityCritical,
+			Summary:  "the nodes cannot reach each other, the docker bridge network may conflict with a host network, eg a VPN",
+			Remediation: "check the host routes, eg with `ip route`, for networks overlapping the node IPs in inspect.json, " +
+				"and configure the docker bridge network (bip in /etc/docker/daemon.json) to not overlap them",
+			Files:   nodeLogs,
+			Pattern: regexp.MustCompile(`dial tcp [0-9a-f.:\[\]]+: connect: (no route to host|network is unreachable)`),
+		},
+		{
+			Name:     "inotify",
+			Severity: SeverityHigh,
+			Summary:  "the host ran out of inotify watches or instances",
+			Remediation: "raise the host limits, eg `sudo sysctl fs.inotify.max_user_watches=524288 fs.inotify.max_user_instances=512`, " +
+				"see " + knownIssues + "#pod-errors-due-to-too-many-open-files",
+			Files: nodeLogs,
+			Pattern: regexp.MustCompile(
+				`too many open files|failed to create fsnotify watcher|inotify_init|` +
+					`inotify_add_watch.*no space left on device`,
+			),
+		},
+		{
+			Name:     "disk-pressure",
+			Severity: SeverityHigh,
+			Summary:  "the host is low on disk space, the kubelet is evicting pods or failing to write",
+			Remediation: "free disk space on the host, eg with `docker system prune`, " +
+				"see " + knownIssues + "#failure-for-cluster-to-properly-start",
+			Files: append([]string{"cluster/nodes-describe.txt", "cluster/resources/events.yaml"}, nodeJournals...),
+			Pattern: regexp.MustCompile(
+				`no space left on device|DiskPressure\s+True|node\.kubernetes\.io/disk-pressure|` +
+					`must evict pod\(s\) to reclaim ephemeral-storage|EvictionThresholdMet|FreeDiskSpaceFailed`,
+			),
+			// running out of inotify watches is also reported as no space
+			Exclude: regexp.MustCompile(`inotify|fsnotify`),
+		},
+		{
+			Name:     "proxy",
+			Severity: SeverityHigh,
+			Summary:  "requests through the configured HTTP proxy are failing",
+			Remediation: "check the proxy in docker-info.txt and the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment, " +
+				"NO_PROXY must include the node, pod and service subnets, see " + knownIssues + "#failing-to-apply-overlay-network",
+			Files:   append([]string{"docker-info.txt"}, nodeLogs...),
+			Pattern: regexp.MustCompile(`proxyconnect tcp|Proxy Authentication Required|407 Proxy|malformed HTTP response.*proxy`),
+		},
+	}
+}
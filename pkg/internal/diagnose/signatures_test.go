@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"testing"
+)
+
+func TestSignatures(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		File     string
+		Line     string
+		Expected []string
+	}{
+		{
+			Name:     "kubelet cgroup driver mismatch",
+			File:     "kind-control-plane/kubelet.log",
+			Line:     `F0101 kubelet[123]: failed to run Kubelet: misconfiguration: kubelet cgroup driver: "systemd" is different from docker cgroup driver: "cgroupfs"`,
+			Expected: []string{"cgroups"},
+		},
+		{
+			Name:     "cgroups v2 host",
+			File:     "kind-worker/serial.log",
+			Line:     "Failed to create cgroup: cgroup mountpoint does not exist: unknown",
+			Expected: []string{"cgroups"},
+		},
+		{
+			Name:     "docker using the systemd cgroup driver",
+			File:     "docker-info.txt",
+			Line:     " Cgroup Driver: systemd",
+			Expected: []string{"host-cgroups"},
+		},
+		{
+			Name:     "docker on cgroups v2",
+			File:     "docker-info.txt",
+			Line:     " Cgroup Version: 2",
+			Expected: []string{"host-cgroups"},
+		},
+		{
+			Name: "docker using the cgroupfs cgroup driver",
+			File: "docker-info.txt",
+			Line: " Cgroup Driver: cgroupfs",
+		},
+		{
+			Name:     "apparmor denial",
+			File:     "kind-worker/journal.log",
+			Line:     `audit: type=1400 apparmor="DENIED" operation="mount" profile="docker-default"`,
+			Expected: []string{"apparmor"},
+		},
+		{
+			Name:     "api server unreachable",
+			File:     "kind-worker/kubelet.log",
+			Line:     `E0101 reflector.go:123] Get https://172.17.0.3:6443/api/v1/nodes: dial tcp 172.17.0.3:6443: connect: no route to host`,
+			Expected: []string{"network-conflict"},
+		},
+		{
+			Name:     "too many open files",
+			File:     "kind-worker/kubelet.log",
+			Line:     "E0101 kuberuntime_manager.go:123] failed to create fsnotify watcher: too many open files",
+			Expected: []string{"inotify"},
+		},
+		{
+			Name:     "out of inotify watches is not disk pressure",
+			File:     "kind-worker/containerd.log",
+			Line:     "inotify_add_watch /sys/fs/cgroup/memory: no space left on device",
+			Expected: []string{"inotify"},
+		},
+		{
+			Name:     "out of disk",
+			File:     "kind-worker/containerd.log",
+			Line:     "failed to extract layer: write /var/lib/containerd/tmp: no space left on device",
+			Expected: []string{"disk-pressure"},
+		},
+		{
+			Name:     "node condition",
+			File:     "cluster/nodes-describe.txt",
+			Line:     "  DiskPressure     True    Mon, 01 Jan 2020 00:00:00 +0000   KubeletHasDiskPressure   kubelet has disk pressure",
+			Expected: []string{"disk-pressure"},
+		},
+		{
+			Name: "healthy node condition",
+			File: "cluster/nodes-describe.txt",
+			Line: "  DiskPressure     False   Mon, 01 Jan 2020 00:00:00 +0000   KubeletHasNoDiskPressure   kubelet has no disk pressure",
+		},
+		{
+			Name:     "proxy failure",
+			File:     "kind-control-plane/containerd.log",
+			Line:     `failed to pull image "k8s.gcr.io/pause:3.1": failed to do request: Head https://k8s.gcr.io/v2/pause/manifests/3.1: proxyconnect tcp: dial tcp 10.0.0.1:3128: i/o timeout`,
+			Expected: []string{"proxy"},
+		},
+		{
+			Name: "signature in a file it does not apply to",
+			File: "kind-worker/pods/kube-system_app/app/0.log",
+			Line: "too many open files",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			matched := []string{}
+			for _, s := range Signatures() {
+				if s.AppliesTo(tc.File) && s.Matches(tc.Line) {
+					matched = append(matched, s.Name)
+				}
+			}
+			if len(matched) != len(tc.Expected) {
+				t.Fatalf("expected signatures %v but got %v", tc.Expected, matched)
+			}
+			for i := range matched {
+				if matched[i] != tc.Expected[i] {
+					t.Fatalf("expected signatures %v but got %v", tc.Expected, matched)
+				}
+			}
+		})
+	}
+}
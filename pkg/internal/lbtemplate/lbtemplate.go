@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lbtemplate parses load balancer config templates, with the
+// functions available to them
+package lbtemplate
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Parse parses text as a load balancer config template called name
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"milliseconds": func(d time.Duration) int64 {
+			return int64(d / time.Millisecond)
+		},
+		"seconds": func(d time.Duration) string {
+			return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+		},
+		"host": func(address string) (string, error) {
+			host, _, err := splitHostPort(address)
+			return host, err
+		},
+		"port": func(address string) (string, error) {
+			_, port, err := splitHostPort(address)
+			return port, err
+		},
+	}).Parse(text)
+}
+
+// splitHostPort splits address into host and port like net.SplitHostPort,
+// but also accepts an IPv6 host without brackets, the port following the
+// last colon
+func splitHostPort(address string) (host, port string, err error) {
+	host, port, err = net.SplitHostPort(address)
+	if err == nil || strings.Contains(address, "[") {
+		return host, port, err
+	}
+	i := strings.LastIndex(address, ":")
+	if i < 0 || net.ParseIP(address[:i]) == nil {
+		return "", "", err
+	}
+	if _, portErr := strconv.ParseUint(address[i+1:], 10, 16); portErr != nil {
+		return "", "", err
+	}
+	return address[:i], address[i+1:], nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lbtemplate
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestHostPort(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Address      string
+		ExpectedHost string
+		ExpectedPort string
+		ExpectError  bool
+	}{
+		{Address: "172.18.0.3:6443", ExpectedHost: "172.18.0.3", ExpectedPort: "6443"},
+		{Address: "[fc00:f853:ccd:e793::3]:6443", ExpectedHost: "fc00:f853:ccd:e793::3", ExpectedPort: "6443"},
+		{Address: "fc00:f853:ccd:e793::3:6443", ExpectedHost: "fc00:f853:ccd:e793::3", ExpectedPort: "6443"},
+		{Address: "kind-control-plane:6443", ExpectedHost: "kind-control-plane", ExpectedPort: "6443"},
+		{Address: "172.18.0.3", ExpectError: true},
+		{Address: "fc00::3", ExpectError: true},
+		{Address: "[fc00::3]", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Address, func(t *testing.T) {
+			t.Parallel()
+			for _, f := range []struct {
+				text     string
+				expected string
+			}{
+				{"{{ host . }}", tc.ExpectedHost},
+				{"{{ port . }}", tc.ExpectedPort},
+			} {
+				tmpl, err := Parse("test", f.text)
+				if err != nil {
+					t.Fatalf("failed to parse %q: %v", f.text, err)
+				}
+				var buff bytes.Buffer
+				err = tmpl.Execute(&buff, tc.Address)
+				assert.ExpectError(t, tc.ExpectError, err)
+				if !tc.ExpectError {
+					assert.StringEqual(t, f.expected, buff.String())
+				}
+			}
+		})
+	}
+}
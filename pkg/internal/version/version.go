@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version contains the kind version
+package version
+
+import (
+	"runtime"
+)
+
+// Version returns the kind CLI Semantic Version
+func Version() string {
+	v := VersionCore
+	// add pre-release version info if we have it
+	if VersionPreRelease != "" {
+		v += "-" + VersionPreRelease
+		// if commit was set, add the + <build>
+		// we only do this for pre-release versions
+		if GitCommit != "" {
+			// NOTE: use 14 character short hash, like Kubernetes
+			v += "+" + truncate(GitCommit, 14)
+		}
+	}
+	return v
+}
+
+// DisplayVersion is Version() display formatted, this is what the version
+// subcommand prints
+func DisplayVersion() string {
+	return "kind v" + Version() + " " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// VersionCore is the core portion of the kind CLI version per Semantic Versioning 2.0.0
+const VersionCore = "0.7.0"
+
+// VersionPreRelease is the pre-release portion of the kind CLI version per
+// Semantic Versioning 2.0.0
+const VersionPreRelease = "alpha"
+
+// GitCommit is the commit used to build the kind binary, if available.
+// It is injected at build time.
+var GitCommit = ""
+
+func truncate(s string, maxLen int) string {
+	if len(s) < maxLen {
+		return s
+	}
+	return s[:maxLen]
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"time"
+)
+
+// EventType identifies the kind of a structured Event
+type EventType string
+
+// These are the EventTypes kind emits
+const (
+	// StatusStartEvent is emitted when a new status phase starts
+	StatusStartEvent EventType = "StatusStart"
+	// StatusEndEvent is emitted when a status phase ends
+	StatusEndEvent EventType = "StatusEnd"
+	// ActionStartEvent is emitted before a cluster create action runs
+	ActionStartEvent EventType = "ActionStart"
+	// ActionEndEvent is emitted after a cluster create action has run
+	ActionEndEvent EventType = "ActionEnd"
+	// InfoEvent is emitted for user facing info messages
+	InfoEvent EventType = "Info"
+	// WarningEvent is emitted for user facing warnings
+	WarningEvent EventType = "Warning"
+	// ErrorEvent is emitted for error messages
+	ErrorEvent EventType = "Error"
+)
+
+// Event is a structured, machine readable progress event
+type Event struct {
+	// Time is when the event occurred
+	Time time.Time `json:"time"`
+	// Type is the type of the event
+	Type EventType `json:"type"`
+	// Step is the name of the status phase or action this event is about
+	Step string `json:"step,omitempty"`
+	// Success is set for StatusEndEvent and ActionEndEvent
+	Success *bool `json:"success,omitempty"`
+	// Message is the message for Info, Warning and Error events
+	Message string `json:"message,omitempty"`
+	// Error is the error text for a failed step, if any
+	Error string `json:"error,omitempty"`
+}
+
+// EventSink may optionally be implemented by a Logger to receive
+// structured progress events
+//
+// kind reports status phases and create actions to loggers implementing
+// this interface, in addition to normal logging
+type EventSink interface {
+	Event(e Event)
+}
+
+// SendEvent sends e to logger if it is an EventSink, otherwise it does
+// nothing. If e.Time is not set it will be set to the current time.
+func SendEvent(logger Logger, e Event) {
+	sink, ok := logger.(EventSink)
+	if !ok {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	sink.Event(e)
+}